@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// applyStep is one target of an apply transaction. snapshot is called for
+// every step before anything is written, so restore can always bring the
+// target back to the state it was in before the transaction started.
+type applyStep interface {
+	describe() string
+	snapshot() error
+	apply() error
+	restore() error
+}
+
+type fileStep struct {
+	name   string
+	path   string
+	render func(old []byte) []byte
+
+	old     []byte
+	existed bool
+	written bool
+}
+
+func newFileStep(name, path string, render func(old []byte) []byte) *fileStep {
+	return &fileStep{name: name, path: path, render: render}
+}
+
+func (s *fileStep) describe() string {
+	return "write " + s.name
+}
+
+func (s *fileStep) snapshot() error {
+	old, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.old, s.existed = nil, false
+			return nil
+		}
+
+		return err
+	}
+
+	s.old, s.existed = old, true
+
+	return nil
+}
+
+func (s *fileStep) apply() error {
+	content := s.render(s.old)
+
+	if s.existed && bytes.Equal(s.old, content) {
+		return nil
+	}
+
+	if !s.existed && len(content) == 0 {
+		return nil
+	}
+
+	if err := writeConfigFile(s.path, content); err != nil {
+		return err
+	}
+
+	s.written = true
+
+	return nil
+}
+
+func (s *fileStep) restore() error {
+	if !s.existed {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	current, err := os.ReadFile(s.path)
+	if err == nil && bytes.Equal(current, s.old) {
+		return nil
+	}
+
+	return writeFileAtomic(s.path, s.old, 0o644)
+}
+
+type backendStep struct {
+	b   backend
+	cfg themeConfig
+
+	old themeConfig
+}
+
+func (s *backendStep) describe() string {
+	return fmt.Sprintf("apply settings through '%s' backend", s.b.name())
+}
+
+func (s *backendStep) snapshot() error {
+	old, err := s.b.read()
+	if err != nil {
+		return err
+	}
+
+	s.old = old
+
+	return nil
+}
+
+func (s *backendStep) apply() error {
+	return s.b.write(s.cfg)
+}
+
+func (s *backendStep) restore() error {
+	return s.b.write(s.old)
+}
+
+// runTransaction applies every step in order. When a step fails, that step
+// and every step before it are restored in reverse order.
+func runTransaction(steps []applyStep) error {
+	for _, step := range steps {
+		if err := step.snapshot(); err != nil {
+			return fmt.Errorf("failed to snapshot before %s: %w; nothing was changed", step.describe(), err)
+		}
+	}
+
+	for i, step := range steps {
+		if err := step.apply(); err != nil {
+			applyErr := fmt.Errorf("failed to %s (step %d of %d): %w", step.describe(), i+1, len(steps), err)
+
+			restoreErrs := []error{}
+
+			for j := i; j >= 0; j-- {
+				if err := steps[j].restore(); err != nil {
+					restoreErrs = append(restoreErrs, fmt.Errorf("failed to roll back %s: %w", steps[j].describe(), err))
+				}
+			}
+
+			if len(restoreErrs) > 0 {
+				return errors.Join(append([]error{applyErr}, restoreErrs...)...)
+			}
+
+			return fmt.Errorf("%w; previous state restored", applyErr)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestRunTransactionRollsBack(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+
+	existing := filepath.Join(tempDir, "existing.ini")
+	created := filepath.Join(tempDir, "sub", "created.ini")
+
+	err := os.WriteFile(existing, []byte("original"), 0o644)
+	test.RequireNoError(t, err)
+
+	render := func(old []byte) []byte {
+		return []byte("changed")
+	}
+
+	oldCfg := themeConfig{gtkTheme: "Old"}
+	b := &fakeBackend{cfg: oldCfg, failWrite: 1}
+
+	steps := []applyStep{
+		newFileStep("existing.ini", existing, render),
+		newFileStep("created.ini", created, render),
+		&backendStep{b: b, cfg: themeConfig{gtkTheme: "New"}},
+	}
+
+	err = runTransaction(steps)
+	if err == nil {
+		t.Fatal("expected transaction to fail")
+	}
+
+	if !strings.Contains(err.Error(), "step 3 of 3") || !strings.Contains(err.Error(), "'fake' backend") {
+		t.Errorf("error %q does not report the failed step", err)
+	}
+
+	content, err := os.ReadFile(existing)
+	test.RequireNoError(t, err)
+
+	if string(content) != "original" {
+		t.Errorf("existing file not restored: got %q", content)
+	}
+
+	if isFile(created) {
+		t.Error("file created by the transaction was not removed")
+	}
+
+	if b.cfg != oldCfg {
+		t.Errorf("backend not restored: got %+v; want %+v", b.cfg, oldCfg)
+	}
+}
+
+func TestRunTransactionApplies(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+	path := filepath.Join(tempDir, "settings.ini")
+
+	b := &fakeBackend{}
+	cfg := themeConfig{gtkTheme: "New", preferDark: true}
+
+	steps := []applyStep{
+		newFileStep("settings.ini", path, func(old []byte) []byte {
+			return renderSettingsIni(old, cfg)
+		}),
+		&backendStep{b: b, cfg: cfg},
+	}
+
+	err := runTransaction(steps)
+	test.RequireNoError(t, err)
+
+	content, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	value, ok := parseINI(content).get("Settings", "gtk-theme-name")
+	if !ok || value != "New" {
+		t.Errorf("got gtk-theme-name %q; want %q", value, "New")
+	}
+
+	if b.cfg != cfg {
+		t.Errorf("got backend config %+v; want %+v", b.cfg, cfg)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+const envXdgCurrentDesktop = "XDG_CURRENT_DESKTOP"
+
+const defaultBackend = "gsettings"
+
+type capability uint
+
+const (
+	capGtkTheme capability = 1 << iota
+	capIconTheme
+	capCursorTheme
+	capColorScheme
+	capCursorSize
+)
+
+var capabilityNames = []struct {
+	cap  capability
+	name string
+}{
+	{capGtkTheme, "gtk"},
+	{capIconTheme, "icon"},
+	{capCursorTheme, "cursor"},
+	{capColorScheme, "color-scheme"},
+	{capCursorSize, "cursor-size"},
+}
+
+func (c capability) has(other capability) bool {
+	return c&other == other
+}
+
+func (c capability) String() string {
+	names := []string{}
+
+	for _, cn := range capabilityNames {
+		if c.has(cn.cap) {
+			names = append(names, cn.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// backend reads and writes the session-wide look settings of a desktop
+// environment. Fields outside of capabilities() are ignored by write and
+// left zero by read.
+type backend interface {
+	name() string
+	available() bool
+	capabilities() capability
+	read() (themeConfig, error)
+	write(cfg themeConfig) error
+}
+
+type backendEntry struct {
+	name     string
+	desktops []string
+	new      func() backend
+}
+
+var backends = []backendEntry{
+	{
+		name:     "gsettings",
+		desktops: []string{"gnome", "gnome-classic", "gnome-flashback", "ubuntu", "unity", "budgie", "pantheon"},
+		new:      newGsettingsBackend,
+	},
+	{
+		name:     "cinnamon",
+		desktops: []string{"x-cinnamon", "cinnamon"},
+		new:      newCinnamonBackend,
+	},
+	{
+		name:     "mate",
+		desktops: []string{"mate"},
+		new:      newMateBackend,
+	},
+	{
+		name:     "xfce",
+		desktops: []string{"xfce"},
+		new:      newXfceBackend,
+	},
+	{
+		name:     "kde",
+		desktops: []string{"kde"},
+		new:      newKdeBackend,
+	},
+	{
+		name:     "xsettingsd",
+		desktops: []string{"i3", "openbox", "bspwm"},
+		new:      newXsettingsdBackend,
+	},
+}
+
+// newBackend creates the named backend. A comma separated list of names,
+// such as "gsettings,cinnamon", creates a backend writing to all of them.
+func newBackend(name string) (backend, error) {
+	if name == "" {
+		name = detectBackend()
+	}
+
+	if strings.Contains(name, ",") {
+		multi := &multiBackend{}
+
+		for _, part := range strings.Split(name, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" || slices.ContainsFunc(multi.backends, func(b backend) bool { return b.name() == part }) {
+				continue
+			}
+
+			b, err := newBackend(part)
+			if err != nil {
+				return nil, err
+			}
+
+			multi.backends = append(multi.backends, b)
+		}
+
+		if len(multi.backends) == 1 {
+			return multi.backends[0], nil
+		}
+
+		return multi, nil
+	}
+
+	for _, entry := range backends {
+		if entry.name == name {
+			return entry.new(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown backend: '%s'. see 'lookctl backends' for available backends", name)
+}
+
+// multiBackend keeps several desktops in sync, for users who switch
+// between sessions. Each setting is read from the first backend that
+// supports it and written to every backend.
+type multiBackend struct {
+	backends []backend
+}
+
+func (m *multiBackend) name() string {
+	names := []string{}
+	for _, b := range m.backends {
+		names = append(names, b.name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+func (m *multiBackend) available() bool {
+	for _, b := range m.backends {
+		if !b.available() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *multiBackend) capabilities() capability {
+	caps := capability(0)
+	for _, b := range m.backends {
+		caps |= b.capabilities()
+	}
+
+	return caps
+}
+
+func (m *multiBackend) read() (themeConfig, error) {
+	cfg := themeConfig{}
+	filled := capability(0)
+
+	for _, b := range m.backends {
+		missing := b.capabilities() &^ filled
+		if missing == 0 {
+			continue
+		}
+
+		other, err := b.read()
+		if err != nil {
+			return themeConfig{}, fmt.Errorf("%s: %w", b.name(), err)
+		}
+
+		if missing.has(capGtkTheme) {
+			cfg.gtkTheme = other.gtkTheme
+		}
+
+		if missing.has(capIconTheme) {
+			cfg.iconTheme = other.iconTheme
+		}
+
+		if missing.has(capCursorTheme) {
+			cfg.cursorTheme = other.cursorTheme
+		}
+
+		if missing.has(capCursorSize) {
+			cfg.cursorSize = other.cursorSize
+		}
+
+		if missing.has(capColorScheme) {
+			cfg.preferDark = other.preferDark
+		}
+
+		filled |= missing
+	}
+
+	return cfg, nil
+}
+
+func (m *multiBackend) write(cfg themeConfig) error {
+	for _, b := range m.backends {
+		if err := b.write(cfg); err != nil {
+			return fmt.Errorf("%s: %w", b.name(), err)
+		}
+	}
+
+	return nil
+}
+
+func detectBackend() string {
+	for _, desktop := range getCurrentDesktops() {
+		for _, entry := range backends {
+			if slices.Contains(entry.desktops, desktop) {
+				return entry.name
+			}
+		}
+	}
+
+	return defaultBackend
+}
+
+func getCurrentDesktops() []string {
+	desktops := []string{}
+
+	for _, desktop := range strings.Split(os.Getenv(envXdgCurrentDesktop), ":") {
+		desktop = strings.ToLower(strings.TrimSpace(desktop))
+		if desktop != "" {
+			desktops = append(desktops, desktop)
+		}
+	}
+
+	return desktops
+}
+
+func warnUnsupported(b backend, cap capability, what string) {
+	if b.capabilities().has(cap) {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "warning: backend '%s' does not support %s; only GTK config files will be updated\n", b.name(), what)
+}
+
+// commandRunner runs an external program and returns its combined output.
+// Backends take one so tests can replace the real programs with a fake.
+type commandRunner func(name string, args ...string) ([]byte, error)
+
+func runCommand(name string, args ...string) ([]byte, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+
+		return out, err
+	}
+
+	return out, nil
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+
+	return err == nil
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+type fakeRunner struct {
+	values map[string]string
+	fail   map[string]bool
+	calls  []string
+}
+
+func newFakeRunner(values map[string]string) *fakeRunner {
+	return &fakeRunner{values: values, fail: map[string]bool{}}
+}
+
+func (f *fakeRunner) run(name string, args ...string) ([]byte, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, call)
+
+	if f.fail[call] {
+		return nil, fmt.Errorf("exit status 1")
+	}
+
+	if len(args) == 3 && args[0] == "get" {
+		return []byte(f.values[args[1]+" "+args[2]] + "\n"), nil
+	}
+
+	if name == "xfconf-query" && len(args) == 4 {
+		value, ok := f.values[args[1]+" "+args[3]]
+		if !ok {
+			return nil, fmt.Errorf("exit status 1: Property \"%s\" does not exist on channel \"%s\"", args[3], args[1])
+		}
+
+		return []byte(value + "\n"), nil
+	}
+
+	return nil, nil
+}
+
+type fakeBackend struct {
+	cfg       themeConfig
+	failWrite int
+	writes    int
+}
+
+func (f *fakeBackend) name() string {
+	return "fake"
+}
+
+func (f *fakeBackend) available() bool {
+	return true
+}
+
+func (f *fakeBackend) capabilities() capability {
+	return capGtkTheme | capIconTheme | capCursorTheme | capColorScheme
+}
+
+func (f *fakeBackend) read() (themeConfig, error) {
+	return f.cfg, nil
+}
+
+func (f *fakeBackend) write(cfg themeConfig) error {
+	f.writes++
+
+	if f.writes == f.failWrite {
+		return errors.New("write failed")
+	}
+
+	f.cfg = cfg
+
+	return nil
+}
+
+func TestDetectBackend(t *testing.T) {
+	tests := []struct {
+		description string
+		desktop     string
+		want        string
+	}{
+		{
+			description: "detects gnome",
+			desktop:     "GNOME",
+			want:        "gsettings",
+		},
+		{
+			description: "detects from colon separated list",
+			desktop:     "ubuntu:GNOME",
+			want:        "gsettings",
+		},
+		{
+			description: "detects cinnamon",
+			desktop:     "X-Cinnamon",
+			want:        "cinnamon",
+		},
+		{
+			description: "detects mate",
+			desktop:     "MATE",
+			want:        "mate",
+		},
+		{
+			description: "detects xfce",
+			desktop:     "XFCE",
+			want:        "xfce",
+		},
+		{
+			description: "detects kde",
+			desktop:     "KDE",
+			want:        "kde",
+		},
+		{
+			description: "detects i3",
+			desktop:     "i3",
+			want:        "xsettingsd",
+		},
+		{
+			description: "detects openbox",
+			desktop:     "openbox",
+			want:        "xsettingsd",
+		},
+		{
+			description: "falls back to default backend",
+			desktop:     "",
+			want:        defaultBackend,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			t.Setenv(envXdgCurrentDesktop, tt.desktop)
+
+			got := detectBackend()
+
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBackendUnknown(t *testing.T) {
+	_, err := newBackend("nonexistent")
+	if err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+func TestGsettingsBackendRead(t *testing.T) {
+	runner := newFakeRunner(map[string]string{
+		gnomeDesktopInterface + " gtk-theme":    "'Adwaita-dark'",
+		gnomeDesktopInterface + " icon-theme":   "'Papirus'",
+		gnomeDesktopInterface + " cursor-theme": "'Bibata'",
+		gnomeDesktopInterface + " cursor-size":  "24",
+		gnomeDesktopInterface + " color-scheme": "'prefer-dark'",
+	})
+
+	b := &gsettingsBackend{run: runner.run, schema: gnomeSchema}
+
+	got, err := b.read()
+	test.RequireNoError(t, err)
+
+	want := themeConfig{
+		gtkTheme:    "Adwaita-dark",
+		iconTheme:   "Papirus",
+		cursorTheme: "Bibata",
+		cursorSize:  24,
+		preferDark:  true,
+	}
+
+	if got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestGsettingsBackendWrite(t *testing.T) {
+	runner := newFakeRunner(nil)
+	runner.fail["gsettings set "+gnomeDesktopInterface+" cursor-theme Bibata"] = true
+
+	b := &gsettingsBackend{run: runner.run, schema: gnomeSchema}
+
+	err := b.write(themeConfig{
+		gtkTheme:    "Adwaita",
+		iconTheme:   "Papirus",
+		cursorTheme: "Bibata",
+	})
+	if err == nil {
+		t.Fatal("expected error when gsettings fails")
+	}
+
+	if !strings.Contains(err.Error(), "cursor theme") {
+		t.Errorf("error %q does not mention the failed key", err)
+	}
+
+	if slices.Contains(runner.calls, "gsettings set "+gnomeDesktopInterface+" color-scheme prefer-light") {
+		t.Error("expected write to stop at the first failure")
+	}
+}
+
+func TestGsettingsBackendWriteCursorSize(t *testing.T) {
+	runner := newFakeRunner(nil)
+	b := &gsettingsBackend{run: runner.run, schema: gnomeSchema}
+
+	cfg := themeConfig{gtkTheme: "Adwaita", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 48}
+	test.RequireNoError(t, b.write(cfg))
+
+	// undoing to a state with the default size
+	cfg.cursorSize = 0
+	test.RequireNoError(t, b.write(cfg))
+
+	for _, want := range []string{
+		"gsettings set " + gnomeDesktopInterface + " cursor-size 48",
+		"gsettings reset " + gnomeDesktopInterface + " cursor-size",
+	} {
+		if !slices.Contains(runner.calls, want) {
+			t.Errorf("missing call %q in %q", want, runner.calls)
+		}
+	}
+}
+
+func TestMateBackendRead(t *testing.T) {
+	runner := newFakeRunner(map[string]string{
+		mateInterface + " gtk-theme":           "'TraditionalOk'",
+		mateInterface + " icon-theme":          "'mate'",
+		matePeripheralsMouse + " cursor-theme": "'mate-black'",
+		matePeripheralsMouse + " cursor-size":  "int32 32",
+	})
+
+	b := &gsettingsBackend{run: runner.run, schema: mateSchema}
+
+	got, err := b.read()
+	test.RequireNoError(t, err)
+
+	want := themeConfig{gtkTheme: "TraditionalOk", iconTheme: "mate", cursorTheme: "mate-black", cursorSize: 32}
+	if got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+
+	if b.capabilities().has(capColorScheme) {
+		t.Error("mate backend should not claim color scheme support")
+	}
+
+	if len(runner.calls) != 4 {
+		t.Errorf("got %d gsettings calls; want 4: %q", len(runner.calls), runner.calls)
+	}
+}
+
+func TestCinnamonBackendWriteShellTheme(t *testing.T) {
+	themeDirPath := setupAssetDir(t, "themes")
+
+	test.CreateEmptyDir(t, filepath.Join(themeDirPath, "Mint-Y", "cinnamon"))
+	test.CreateEmptyFile(t, filepath.Join(themeDirPath, "Mint-Y", "index.theme"))
+	test.CreateEmptyDir(t, filepath.Join(themeDirPath, "Plain"))
+	test.CreateEmptyFile(t, filepath.Join(themeDirPath, "Plain", "index.theme"))
+
+	runner := newFakeRunner(nil)
+	b := &gsettingsBackend{run: runner.run, schema: cinnamonSchema}
+
+	test.RequireNoError(t, b.write(themeConfig{gtkTheme: "Mint-Y", iconTheme: "Mint-Y", cursorTheme: "Bibata", preferDark: true}))
+
+	want := []string{
+		"gsettings set " + cinnamonDesktopInterface + " gtk-theme Mint-Y",
+		"gsettings set org.cinnamon.theme name Mint-Y",
+		"gsettings set " + cinnamonDesktopInterface + " icon-theme Mint-Y",
+		"gsettings set " + cinnamonDesktopInterface + " cursor-theme Bibata",
+		"gsettings reset " + cinnamonDesktopInterface + " cursor-size",
+		"gsettings set org.x.apps.portal color-scheme prefer-dark",
+	}
+
+	if !slices.Equal(runner.calls, want) {
+		t.Errorf("got calls %q; want %q", runner.calls, want)
+	}
+
+	runner.calls = nil
+	test.RequireNoError(t, b.write(themeConfig{gtkTheme: "Plain"}))
+
+	if slices.Contains(runner.calls, "gsettings set org.cinnamon.theme name Plain") {
+		t.Error("set the shell theme for a theme without a cinnamon directory")
+	}
+}
+
+func TestMultiBackend(t *testing.T) {
+	runner := newFakeRunner(map[string]string{
+		mateInterface + " gtk-theme":            "'TraditionalOk'",
+		mateInterface + " icon-theme":           "'mate'",
+		matePeripheralsMouse + " cursor-theme":  "'mate-black'",
+		gnomeDesktopInterface + " gtk-theme":    "'Adwaita'",
+		gnomeDesktopInterface + " color-scheme": "'prefer-dark'",
+	})
+
+	multi := &multiBackend{backends: []backend{
+		&gsettingsBackend{run: runner.run, schema: mateSchema},
+		&gsettingsBackend{run: runner.run, schema: gnomeSchema},
+	}}
+
+	if multi.name() != "mate,gsettings" {
+		t.Errorf("got name %q", multi.name())
+	}
+
+	got, err := multi.read()
+	test.RequireNoError(t, err)
+
+	// the color scheme comes from gnome, everything else from mate
+	want := themeConfig{gtkTheme: "TraditionalOk", iconTheme: "mate", cursorTheme: "mate-black", preferDark: true}
+	if got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+
+	runner.calls = nil
+	test.RequireNoError(t, multi.write(themeConfig{gtkTheme: "Adwaita", iconTheme: "Papirus", cursorTheme: "Bibata"}))
+
+	for _, call := range []string{
+		"gsettings set " + mateInterface + " gtk-theme Adwaita",
+		"gsettings set " + gnomeDesktopInterface + " gtk-theme Adwaita",
+	} {
+		if !slices.Contains(runner.calls, call) {
+			t.Errorf("missing call %q in %q", call, runner.calls)
+		}
+	}
+}
+
+func TestNewBackendList(t *testing.T) {
+	b, err := newBackend("gsettings, cinnamon,gsettings")
+	test.RequireNoError(t, err)
+
+	if b.name() != "gsettings,cinnamon" {
+		t.Errorf("got %q; want %q", b.name(), "gsettings,cinnamon")
+	}
+
+	if _, err := newBackend("gsettings,nonexistent"); err == nil {
+		t.Error("expected error for an unknown backend in the list")
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const backupTimeLayout = "20060102T150405Z"
+
+type backupEntry struct {
+	time     time.Time
+	original string
+	path     string
+}
+
+func getBackupDir() string {
+	return filepath.Join(getStateDir(), "lookctl", "backups")
+}
+
+// writeConfigFile writes a file lookctl manages: the original is backed up
+// the first time it is modified and the new content is written atomically.
+func writeConfigFile(path string, data []byte) error {
+	if err := backupOriginal(path); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// writeFileAtomic replaces path by writing a temporary file next to it and
+// renaming it over the original, so readers never see a partial file.
+// Existing permissions and ownership are kept; perm is only used for new
+// files. Symlinks are followed so the link itself stays in place.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	info, statErr := os.Stat(path)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".lookctl-*")
+	if err != nil {
+		return err
+	}
+
+	tmpName := tmp.Name()
+
+	cleanup := func(err error) error {
+		tmp.Close()
+		os.Remove(tmpName)
+
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		return cleanup(err)
+	}
+
+	if statErr == nil {
+		perm = info.Mode().Perm()
+
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			if err := tmp.Chown(int(stat.Uid), int(stat.Gid)); err != nil && !errors.Is(err, os.ErrPermission) {
+				return cleanup(err)
+			}
+		}
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		return cleanup(err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return cleanup(err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return cleanup(err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	return nil
+}
+
+// backupOriginal copies path into a timestamped directory under the backup
+// dir, mirroring its absolute path. Only the first version lookctl ever
+// modified is kept, so the backup is always the user's original file.
+func backupOriginal(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	backups, err := getBackups()
+	if err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(backups, func(b backupEntry) bool { return b.original == abs }) {
+		return nil
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(getBackupDir(), time.Now().UTC().Format(backupTimeLayout), abs)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(dest, data, info.Mode().Perm())
+}
+
+// getBackups returns every backup sorted by time, oldest first.
+func getBackups() ([]backupEntry, error) {
+	backupDir := getBackupDir()
+
+	stamps, err := os.ReadDir(backupDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	backups := []backupEntry{}
+
+	for _, stamp := range stamps {
+		t, err := time.Parse(backupTimeLayout, stamp.Name())
+		if err != nil || !stamp.IsDir() {
+			continue
+		}
+
+		root := filepath.Join(backupDir, stamp.Name())
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || strings.Contains(d.Name(), ".lookctl-") {
+				return nil
+			}
+
+			backups = append(backups, backupEntry{
+				time:     t,
+				original: strings.TrimPrefix(path, root),
+				path:     path,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortStableFunc(backups, func(a, b backupEntry) int {
+		return a.time.Compare(b.time)
+	})
+
+	return backups, nil
+}
+
+func restoreBackup(entry backupEntry) error {
+	data, err := os.ReadFile(entry.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(entry.path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.original), 0o755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(entry.original, data, info.Mode().Perm())
+}
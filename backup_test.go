@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tempDir := t.TempDir()
+
+	path := filepath.Join(tempDir, "settings.ini")
+	link := filepath.Join(tempDir, "link.ini")
+
+	err := os.WriteFile(path, []byte("old"), 0o600)
+	test.RequireNoError(t, err)
+
+	err = os.Symlink(path, link)
+	test.RequireNoError(t, err)
+
+	err = writeFileAtomic(link, []byte("new"), 0o644)
+	test.RequireNoError(t, err)
+
+	info, err := os.Lstat(link)
+	test.RequireNoError(t, err)
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink was replaced by a regular file")
+	}
+
+	info, err = os.Stat(path)
+	test.RequireNoError(t, err)
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("got permissions %o; want %o", info.Mode().Perm(), 0o600)
+	}
+
+	content, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	if string(content) != "new" {
+		t.Errorf("got content %q; want %q", content, "new")
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	test.RequireNoError(t, err)
+
+	if len(entries) != 2 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestWriteConfigFileKeepsOriginalBackup(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+
+	path := filepath.Join(tempDir, "config", "settings.ini")
+
+	test.CreateEmptyDir(t, filepath.Dir(path))
+
+	err := os.WriteFile(path, []byte("original"), 0o644)
+	test.RequireNoError(t, err)
+
+	test.RequireNoError(t, writeConfigFile(path, []byte("first")))
+	test.RequireNoError(t, writeConfigFile(path, []byte("second")))
+
+	backups, err := getBackups()
+	test.RequireNoError(t, err)
+
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups; want 1", len(backups))
+	}
+
+	if backups[0].original != path {
+		t.Errorf("got original path %q; want %q", backups[0].original, path)
+	}
+
+	test.RequireNoError(t, restoreBackup(backups[0]))
+
+	content, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	if string(content) != "original" {
+		t.Errorf("got restored content %q; want %q", content, "original")
+	}
+}
+
+func TestWriteConfigFileSkipsBackupForNewFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+
+	path := filepath.Join(tempDir, "config", "new.ini")
+
+	test.RequireNoError(t, writeConfigFile(path, []byte("content")))
+
+	backups, err := getBackups()
+	test.RequireNoError(t, err)
+
+	if len(backups) != 0 {
+		t.Errorf("got %d backups; want 0", len(backups))
+	}
+}
@@ -2,15 +2,24 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 )
 
-func list(args []string) error {
+func list(opts globalOptions, args []string) error {
 	fs := newFlagSet("list")
 
 	showGtk := fs.Bool("gtk", false, "Show installed gtk themes")
 	showIcon := fs.Bool("icon", false, "Show installed icon themes")
 	showCursor := fs.Bool("cursor", false, "Show installed cursor themes")
+	templateText := fs.String("template", "", "Format each theme with a Go template")
+	allLocations := fs.Bool("all-locations", false, "Show shadowed copies of themes")
 
 	if err := parseFlag(fs, args, printListHelp); err != nil {
 		return err
@@ -20,39 +29,88 @@ func list(args []string) error {
 		return fmt.Errorf("'list' does not accept arguments; use flags instead")
 	}
 
-	if fs.NFlag() == 0 {
+	if !*showGtk && !*showIcon && !*showCursor {
 		*showGtk = true
 	}
 
-	tw := newTabWriter(os.Stdout)
+	if *templateText != "" && opts.format != formatText {
+		return fmt.Errorf("'-template' cannot be combined with '-format %s'", opts.format)
+	}
+
+	type listSection struct {
+		title  string
+		assets []asset
+	}
+
+	sections := []listSection{}
 
 	if *showGtk {
-		fmt.Fprintf(tw, "GTK Themes:\n")
+		sections = append(sections, listSection{"GTK Themes", findInstalledThemes()})
+	}
+
+	if *showIcon {
+		sections = append(sections, listSection{"Icon Themes", findInstalledIconThemes()})
+	}
 
-		gtkThemes := getInstalledThemes()
+	if *showCursor {
+		sections = append(sections, listSection{"Cursor Themes", findInstalledCursorThemes()})
+	}
 
-		for i, theme := range gtkThemes {
-			fmt.Fprintf(tw, "\t[%d]\t%s\n", i+1, theme)
+	if !*allLocations {
+		for i := range sections {
+			sections[i].assets = slices.DeleteFunc(sections[i].assets, func(a asset) bool {
+				return a.shadowed
+			})
 		}
 	}
 
-	if *showIcon {
-		fmt.Fprintf(tw, "Icon Themes:\n")
+	if *templateText != "" {
+		tmpl, err := parseOutputTemplate(*templateText)
+		if err != nil {
+			return err
+		}
 
-		iconThemes := getInstalledIconThemes()
+		for _, section := range sections {
+			for _, view := range newAssetViews(section.assets) {
+				if err := writeTemplate(os.Stdout, tmpl, view); err != nil {
+					return err
+				}
+			}
+		}
 
-		for i, theme := range iconThemes {
-			fmt.Fprintf(tw, "\t[%d]\t%s\n", i+1, theme)
+		return nil
+	}
+
+	if opts.format != formatText {
+		views := []assetView{}
+		for _, section := range sections {
+			views = append(views, newAssetViews(section.assets)...)
 		}
+
+		return writeStructured(os.Stdout, opts.format, views)
 	}
 
-	if *showCursor {
-		fmt.Fprintf(tw, "Cursor Themes:\n")
+	tw := newTabWriter(os.Stdout)
 
-		cursorThemes := getInstalledCursorThemes()
+	for _, section := range sections {
+		fmt.Fprintf(tw, "%s:\n", section.title)
 
-		for i, theme := range cursorThemes {
-			fmt.Fprintf(tw, "\t[%d]\t%s\n", i+1, theme)
+		n := 0
+
+		for _, a := range section.assets {
+			if !*allLocations {
+				n++
+				fmt.Fprintf(tw, "\t[%d]\t%s\n", n, a.name)
+				continue
+			}
+
+			if a.shadowed {
+				fmt.Fprintf(tw, "\t\t%s\t%s (shadowed)\n", a.name, a.path)
+				continue
+			}
+
+			n++
+			fmt.Fprintf(tw, "\t[%d]\t%s\t%s\n", n, a.name, a.path)
 		}
 	}
 
@@ -61,47 +119,75 @@ func list(args []string) error {
 	return nil
 }
 
-func current(args []string) error {
+func current(opts globalOptions, args []string) error {
 	fs := newFlagSet("current")
 
+	templateText := fs.String("template", "", "Format the output with a Go template")
+
 	if err := parseFlag(fs, args, printCurrentHelp); err != nil {
 		return err
 	}
 
-	if fs.NFlag() > 0 || fs.NArg() > 0 {
-		return fmt.Errorf("'current' accepts no flags or arguments")
+	if fs.NArg() > 0 {
+		return fmt.Errorf("'current' does not accept arguments")
+	}
+
+	if *templateText != "" && opts.format != formatText {
+		return fmt.Errorf("'-template' cannot be combined with '-format %s'", opts.format)
+	}
+
+	var tmpl *template.Template
+	if *templateText != "" {
+		parsed, err := parseOutputTemplate(*templateText)
+		if err != nil {
+			return err
+		}
+
+		tmpl = parsed
+	}
+
+	b, err := newBackend(opts.backend)
+	if err != nil {
+		return err
 	}
 
-	currentTheme, err := getCurrentTheme()
+	currentTheme, err := b.read()
 	if err != nil {
 		return err
 	}
 
+	if tmpl != nil {
+		return writeTemplate(os.Stdout, tmpl, newCurrentView(b, currentTheme))
+	}
+
+	if opts.format != formatText {
+		return writeStructured(os.Stdout, opts.format, newCurrentView(b, currentTheme))
+	}
+
 	tw := newTabWriter(os.Stdout)
 
 	fmt.Fprintf(tw, "GTK Theme\t: %s\n", currentTheme.gtkTheme)
 	fmt.Fprintf(tw, "Icon Theme\t: %s\n", currentTheme.iconTheme)
 	fmt.Fprintf(tw, "Cursor Theme\t: %s\n", currentTheme.cursorTheme)
+	fmt.Fprintf(tw, "Cursor Size\t: %s\n", formatCursorSize(currentTheme.cursorSize))
 
-	colorScheme := "light"
-	if currentTheme.preferDark {
-		colorScheme = "dark"
-	}
-
-	fmt.Fprintf(tw, "Color Scheme\t: %s\n", colorScheme)
+	fmt.Fprintf(tw, "Color Scheme\t: %s\n", currentTheme.colorScheme())
+	fmt.Fprintf(tw, "Backend\t: %s\n", b.name())
 
 	tw.Flush()
 
 	return nil
 }
 
-func set(args []string) error {
+func set(opts globalOptions, args []string) error {
 	fs := newFlagSet("set")
 
 	gtkTheme := fs.String("gtk", "", "Set gtk theme")
 	iconTheme := fs.String("icon", "", "Set icon theme")
 	cursorTheme := fs.String("cursor", "", "Set cursor theme")
+	cursorSize := fs.Int("cursor-size", 0, "Set cursor size")
 	colorScheme := fs.String("color-scheme", "", "Manually set color scheme")
+	force := fs.Bool("force", false, "Apply themes with broken inheritance chains")
 
 	if err := parseFlag(fs, args, printSetHelp); err != nil {
 		return err
@@ -111,40 +197,63 @@ func set(args []string) error {
 		return fmt.Errorf("'set' does not accept arguments; use flags instead")
 	}
 
-	if fs.NFlag() == 0 {
+	if *gtkTheme == "" && *iconTheme == "" && *cursorTheme == "" && *cursorSize == 0 && *colorScheme == "" {
 		return fmt.Errorf("please specify one or more flags")
 	}
 
-	currentCfg, err := getCurrentTheme()
+	b, err := newBackend(opts.backend)
+	if err != nil {
+		return err
+	}
+
+	currentCfg, err := b.read()
 	if err != nil {
 		return err
 	}
 
+	before := currentCfg
+
 	if *gtkTheme != "" {
+		warnUnsupported(b, capGtkTheme, "gtk themes")
+
 		if err := setTheme(&currentCfg, *gtkTheme); err != nil {
 			return err
 		}
 	}
 
 	if *iconTheme != "" {
-		if err := setIconTheme(&currentCfg, *iconTheme); err != nil {
+		warnUnsupported(b, capIconTheme, "icon themes")
+
+		if err := setIconTheme(&currentCfg, *iconTheme, *force); err != nil {
 			return err
 		}
 	}
 
 	if *cursorTheme != "" {
+		warnUnsupported(b, capCursorTheme, "cursor themes")
+
 		if err := setCursorTheme(&currentCfg, *cursorTheme); err != nil {
 			return err
 		}
 	}
 
+	if *cursorSize != 0 {
+		warnUnsupported(b, capCursorSize, "cursor sizes")
+
+		if err := setCursorSize(&currentCfg, *cursorSize); err != nil {
+			return err
+		}
+	}
+
 	if *colorScheme != "" {
+		warnUnsupported(b, capColorScheme, "color schemes")
+
 		if err := setColorScheme(&currentCfg, *colorScheme); err != nil {
 			return err
 		}
 	}
 
-	if err := saveCurrentTheme(currentCfg); err != nil {
+	if err := applyTheme(b, before, currentCfg, historySet); err != nil {
 		return err
 	}
 
@@ -152,3 +261,829 @@ func set(args []string) error {
 
 	return nil
 }
+
+func listBackends(opts globalOptions, args []string) error {
+	fs := newFlagSet("backends")
+
+	if err := parseFlag(fs, args, printBackendsHelp); err != nil {
+		return err
+	}
+
+	if fs.NFlag() > 0 || fs.NArg() > 0 {
+		return fmt.Errorf("'backends' accepts no flags or arguments")
+	}
+
+	selected := opts.backend
+	if selected == "" {
+		selected = detectBackend()
+	}
+
+	tw := newTabWriter(os.Stdout)
+
+	fmt.Fprintf(tw, "Backends:\n")
+
+	for _, entry := range backends {
+		b := entry.new()
+
+		status := "available"
+		if !b.available() {
+			status = "unavailable"
+		}
+
+		if slices.ContainsFunc(strings.Split(selected, ","), func(name string) bool { return strings.TrimSpace(name) == entry.name }) {
+			status += ", selected"
+		}
+
+		fmt.Fprintf(tw, "\t%s\t%s\t(%s)\n", b.name(), b.capabilities(), status)
+	}
+
+	tw.Flush()
+
+	return nil
+}
+
+func restoreBackups(args []string) error {
+	fs := newFlagSet("restore-backup")
+
+	listOnly := fs.Bool("list", false, "Show available backups")
+
+	if err := parseFlag(fs, args, printRestoreBackupHelp); err != nil {
+		return err
+	}
+
+	backups, err := getBackups()
+	if err != nil {
+		return fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	if *listOnly {
+		if fs.NArg() != 0 {
+			return fmt.Errorf("'-list' does not accept arguments")
+		}
+
+		tw := newTabWriter(os.Stdout)
+
+		for _, backup := range backups {
+			fmt.Fprintf(tw, "%s\t%s\n", backup.time.Local().Format(time.DateTime), backup.original)
+		}
+
+		tw.Flush()
+
+		return nil
+	}
+
+	wanted := []string{}
+	for _, arg := range fs.Args() {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+
+		wanted = append(wanted, abs)
+	}
+
+	restored := map[string]bool{}
+
+	for _, backup := range backups {
+		if restored[backup.original] {
+			continue
+		}
+
+		if len(wanted) > 0 && !slices.Contains(wanted, backup.original) {
+			continue
+		}
+
+		if err := restoreBackup(backup); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", backup.original, err)
+		}
+
+		restored[backup.original] = true
+
+		fmt.Fprintf(os.Stdout, "restored %s\n", backup.original)
+	}
+
+	for _, path := range wanted {
+		if !restored[path] {
+			return fmt.Errorf("no backup found for %s", path)
+		}
+	}
+
+	if len(restored) == 0 {
+		fmt.Fprintf(os.Stdout, "no backups to restore\n")
+	}
+
+	return nil
+}
+
+func history(args []string) error {
+	fs := newFlagSet("history")
+
+	if err := parseFlag(fs, args, printHistoryHelp); err != nil {
+		return err
+	}
+
+	if fs.NFlag() > 0 || fs.NArg() > 0 {
+		return fmt.Errorf("'history' accepts no flags or arguments")
+	}
+
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stdout, "no history yet\n")
+		return nil
+	}
+
+	tw := newTabWriter(os.Stdout)
+
+	for i, entry := range entries {
+		fmt.Fprintf(tw, "[%d]\t%s\t%s\t%s\n", i+1, entry.Time.Local().Format(time.DateTime), entry.Action, entry.summary())
+	}
+
+	tw.Flush()
+
+	return nil
+}
+
+func undo(opts globalOptions, args []string) error {
+	return replayHistory(opts, args, historyUndo)
+}
+
+func redo(opts globalOptions, args []string) error {
+	return replayHistory(opts, args, historyRedo)
+}
+
+func replayHistory(opts globalOptions, args []string, action string) error {
+	fs := newFlagSet(action)
+
+	printHelp := printUndoHelp
+	if action == historyRedo {
+		printHelp = printRedoHelp
+	}
+
+	if err := parseFlag(fs, args, printHelp); err != nil {
+		return err
+	}
+
+	if fs.NFlag() > 0 || fs.NArg() > 0 {
+		return fmt.Errorf("'%s' accepts no flags or arguments", action)
+	}
+
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	undoStack, redoStack := getHistoryStacks(entries)
+
+	var target themeConfig
+
+	switch action {
+	case historyUndo:
+		if len(undoStack) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+
+		target = undoStack[len(undoStack)-1].Before.config()
+	case historyRedo:
+		if len(redoStack) == 0 {
+			return fmt.Errorf("nothing to redo")
+		}
+
+		target = redoStack[len(redoStack)-1].After.config()
+	}
+
+	b, err := newBackend(opts.backend)
+	if err != nil {
+		return err
+	}
+
+	currentCfg, err := b.read()
+	if err != nil {
+		return err
+	}
+
+	if err := applyTheme(b, currentCfg, target, action); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "%s: %s\n", action, historyEntry{Before: currentCfg.record(), After: target.record()}.summary())
+
+	return nil
+}
+
+func profile(opts globalOptions, args []string) error {
+	fs := newFlagSet("profile")
+
+	force := fs.Bool("force", false, "Apply icon themes with broken inheritance chains")
+
+	positional, err := parseInterspersed(fs, args, printProfileHelp)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		printProfileHelp(newTabWriter(os.Stderr))
+		return fmt.Errorf("please specify a profile command")
+	}
+
+	subCmd := positional[0]
+	subArgs := positional[1:]
+
+	if subCmd == "list" {
+		if len(subArgs) != 0 {
+			return fmt.Errorf("'profile list' does not accept arguments")
+		}
+
+		names, err := listProfiles()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			fmt.Fprintln(os.Stdout, name)
+		}
+
+		return nil
+	}
+
+	if len(subArgs) != 1 {
+		return fmt.Errorf("'profile %s' requires exactly one profile name", subCmd)
+	}
+
+	name := subArgs[0]
+
+	switch subCmd {
+	case "save":
+		b, err := newBackend(opts.backend)
+		if err != nil {
+			return err
+		}
+
+		currentCfg, err := b.read()
+		if err != nil {
+			return err
+		}
+
+		if err := saveProfile(name, currentCfg); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "profile '%s' saved\n", name)
+	case "apply":
+		record, err := loadProfile(name)
+		if err != nil {
+			return err
+		}
+
+		if missing := getMissingComponents(record.config()); len(missing) > 0 {
+			return fmt.Errorf("profile '%s' uses components that are not installed: %s", name, strings.Join(missing, ", "))
+		}
+
+		b, err := newBackend(opts.backend)
+		if err != nil {
+			return err
+		}
+
+		currentCfg, err := b.read()
+		if err != nil {
+			return err
+		}
+
+		cfg := currentCfg
+
+		if err := mergeProfile(&cfg, record, *force); err != nil {
+			return err
+		}
+
+		if err := applyTheme(b, currentCfg, cfg, historyProfile); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "profile '%s' applied\n", name)
+	case "show":
+		record, err := loadProfile(name)
+		if err != nil {
+			return err
+		}
+
+		cfg := record.config()
+
+		tw := newTabWriter(os.Stdout)
+
+		fmt.Fprintf(tw, "GTK Theme\t: %s\n", cfg.gtkTheme)
+		fmt.Fprintf(tw, "Icon Theme\t: %s\n", cfg.iconTheme)
+		fmt.Fprintf(tw, "Cursor Theme\t: %s\n", cfg.cursorTheme)
+		fmt.Fprintf(tw, "Cursor Size\t: %s\n", formatCursorSize(cfg.cursorSize))
+		fmt.Fprintf(tw, "Color Scheme\t: %s\n", cfg.colorScheme())
+
+		tw.Flush()
+
+		for _, component := range getMissingComponents(cfg) {
+			fmt.Fprintf(os.Stderr, "warning: %s is not installed\n", component)
+		}
+	case "delete":
+		if err := deleteProfile(name); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "profile '%s' deleted\n", name)
+	default:
+		return fmt.Errorf("unknown profile command: '%s'. see 'lookctl profile -h' for more information", subCmd)
+	}
+
+	return nil
+}
+
+func info(opts globalOptions, args []string) error {
+	fs := newFlagSet("info")
+
+	if err := parseFlag(fs, args, printInfoHelp); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("'info' requires exactly one theme name")
+	}
+
+	name := fs.Arg(0)
+
+	found := findThemesByName(name)
+	if len(found) == 0 {
+		return fmt.Errorf("theme '%s' not found. see 'lookctl list' for installed themes", name)
+	}
+
+	infos := []themeInfo{}
+
+	for _, a := range found {
+		details, err := getThemeInfo(a)
+		if err != nil {
+			return err
+		}
+
+		infos = append(infos, details)
+	}
+
+	if opts.format != formatText {
+		return writeStructured(os.Stdout, opts.format, infos)
+	}
+
+	tw := newTabWriter(os.Stdout)
+
+	for i, details := range infos {
+		if i > 0 {
+			fmt.Fprintln(tw, "")
+		}
+
+		fmt.Fprintf(tw, "Kind\t: %s\n", details.Kind)
+		fmt.Fprintf(tw, "Path\t: %s\n", details.Path)
+		fmt.Fprintf(tw, "Name\t: %s\n", details.Name)
+
+		locales := slices.Sorted(maps.Keys(details.LocalizedNames))
+		for _, locale := range locales {
+			fmt.Fprintf(tw, "Name[%s]\t: %s\n", locale, details.LocalizedNames[locale])
+		}
+
+		fmt.Fprintf(tw, "Comment\t: %s\n", details.Comment)
+		fmt.Fprintf(tw, "Inherits\t: %s\n", strings.Join(details.Inherits, ", "))
+		fmt.Fprintf(tw, "Directories\t: %s\n", strings.Join(details.Directories, ", "))
+		fmt.Fprintf(tw, "GTK Versions\t: %s\n", strings.Join(details.GTKVersions, ", "))
+	}
+
+	tw.Flush()
+
+	return nil
+}
+
+func icon(opts globalOptions, args []string) error {
+	fs := newFlagSet("icon")
+
+	if err := parseFlag(fs, args, printIconHelp); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		printIconHelp(newTabWriter(os.Stderr))
+		return fmt.Errorf("please specify an icon command")
+	}
+
+	subCmd := fs.Arg(0)
+	subArgs := fs.Args()[1:]
+
+	switch subCmd {
+	case "lookup":
+		return iconLookup(opts, subArgs)
+	case "cache":
+		return iconCache(opts, subArgs)
+	case "coverage":
+		return iconCoverageReport(opts, subArgs)
+	default:
+		return fmt.Errorf("unknown icon command: '%s'. see 'lookctl icon -h' for more information", subCmd)
+	}
+}
+
+func iconLookup(opts globalOptions, args []string) error {
+	fs := newFlagSet("icon lookup")
+
+	size := fs.Int("size", 48, "Icon size")
+	scale := fs.Int("scale", 1, "Icon scale")
+	themeName := fs.String("theme", "", "Icon theme")
+
+	positional, err := parseInterspersed(fs, args, printIconLookupHelp)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return fmt.Errorf("'icon lookup' requires exactly one icon name")
+	}
+
+	if *size <= 0 || *scale <= 0 {
+		return fmt.Errorf("size and scale must be positive")
+	}
+
+	if *themeName == "" {
+		b, err := newBackend(opts.backend)
+		if err != nil {
+			return err
+		}
+
+		currentCfg, err := b.read()
+		if err != nil {
+			return err
+		}
+
+		*themeName = currentCfg.iconTheme
+	}
+
+	result, found := findIcon(*themeName, positional[0], *size, *scale)
+	if !found {
+		return fmt.Errorf("icon '%s' not found in '%s', its parents, or hicolor", positional[0], *themeName)
+	}
+
+	if opts.format != formatText {
+		return writeStructured(os.Stdout, opts.format, result)
+	}
+
+	fmt.Fprintln(os.Stdout, result.Path)
+
+	return nil
+}
+
+func iconCoverageReport(opts globalOptions, args []string) error {
+	fs := newFlagSet("icon coverage")
+
+	if err := parseFlag(fs, args, printIconCoverageHelp); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("'icon coverage' accepts at most one theme name")
+	}
+
+	themeName := fs.Arg(0)
+
+	if themeName == "" {
+		b, err := newBackend(opts.backend)
+		if err != nil {
+			return err
+		}
+
+		currentCfg, err := b.read()
+		if err != nil {
+			return err
+		}
+
+		themeName = currentCfg.iconTheme
+	}
+
+	coverage, err := getIconCoverage(themeName)
+	if err != nil {
+		return err
+	}
+
+	if opts.format != formatText {
+		return writeStructured(os.Stdout, opts.format, coverage)
+	}
+
+	tw := newTabWriter(os.Stdout)
+
+	fmt.Fprintf(tw, "Theme\t: %s\n", coverage.Theme)
+	fmt.Fprintf(tw, "Icons\t: %d\n", coverage.Total)
+	fmt.Fprintf(tw, "Native\t: %d\n", len(coverage.Native))
+	fmt.Fprintf(tw, "Fallback\t: %d\n", len(coverage.Fallback))
+	fmt.Fprintf(tw, "Missing\t: %d\n", len(coverage.Missing))
+	fmt.Fprintf(tw, "Score\t: %.1f%%\n", coverage.Score)
+
+	if len(coverage.Fallback) > 0 {
+		fmt.Fprintf(tw, "\nFalls back to hicolor:\n")
+
+		for _, usage := range coverage.Fallback {
+			fmt.Fprintf(tw, "\t%s\t%s\n", usage.Icon, strings.Join(usage.Apps, ", "))
+		}
+	}
+
+	if len(coverage.Missing) > 0 {
+		fmt.Fprintf(tw, "\nMissing:\n")
+
+		for _, usage := range coverage.Missing {
+			fmt.Fprintf(tw, "\t%s\t%s\n", usage.Icon, strings.Join(usage.Apps, ", "))
+		}
+	}
+
+	tw.Flush()
+
+	return nil
+}
+
+func check(opts globalOptions, args []string) error {
+	fs := newFlagSet("check")
+
+	if err := parseFlag(fs, args, printCheckHelp); err != nil {
+		return err
+	}
+
+	themeNames := fs.Args()
+
+	if len(themeNames) == 0 {
+		b, err := newBackend(opts.backend)
+		if err != nil {
+			return err
+		}
+
+		currentCfg, err := b.read()
+		if err != nil {
+			return err
+		}
+
+		themeNames = []string{currentCfg.iconTheme}
+	}
+
+	broken := 0
+
+	for _, name := range themeNames {
+		chain, problems, err := checkIconThemeInheritance(name)
+		if err != nil {
+			return err
+		}
+
+		if len(problems) == 0 {
+			fmt.Fprintf(os.Stdout, "%s: ok (%s)\n", name, strings.Join(chain, " -> "))
+			continue
+		}
+
+		broken++
+
+		fmt.Fprintf(os.Stdout, "%s: %d problem(s)\n", name, len(problems))
+
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stdout, "  - %s\n", problem)
+		}
+	}
+
+	if broken > 0 {
+		return fmt.Errorf("%d of %d icon theme(s) have a broken inheritance chain", broken, len(themeNames))
+	}
+
+	return nil
+}
+
+func cursor(opts globalOptions, args []string) error {
+	fs := newFlagSet("cursor")
+
+	if err := parseFlag(fs, args, printCursorHelp); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		printCursorHelp(newTabWriter(os.Stderr))
+		return fmt.Errorf("please specify a cursor command")
+	}
+
+	subCmd := fs.Arg(0)
+	subArgs := fs.Args()[1:]
+
+	switch subCmd {
+	case "info":
+		return cursorInfo(opts, subArgs)
+	case "preview":
+		return cursorPreview(subArgs)
+	case "convert":
+		return cursorConvert(subArgs)
+	default:
+		return fmt.Errorf("unknown cursor command: '%s'. see 'lookctl cursor -h' for more information", subCmd)
+	}
+}
+
+func cursorInfo(opts globalOptions, args []string) error {
+	fs := newFlagSet("cursor info")
+
+	if err := parseFlag(fs, args, printCursorInfoHelp); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("'cursor info' requires exactly one cursor theme name")
+	}
+
+	info, err := getCursorThemeInfo(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if opts.format != formatText {
+		return writeStructured(os.Stdout, opts.format, info)
+	}
+
+	formatSizes := func(sizes []cursorSize) string {
+		parts := []string{}
+		for _, size := range sizes {
+			parts = append(parts, fmt.Sprintf("%d (%d)", size.Size, size.Frames))
+		}
+
+		return strings.Join(parts, ", ")
+	}
+
+	sizes := []string{}
+	for _, size := range info.Sizes {
+		sizes = append(sizes, strconv.Itoa(size))
+	}
+
+	tw := newTabWriter(os.Stdout)
+
+	fmt.Fprintf(tw, "Theme\t: %s\n", info.Theme)
+	fmt.Fprintf(tw, "Path\t: %s\n", info.Path)
+	fmt.Fprintf(tw, "Sizes\t: %s\n", strings.Join(sizes, ", "))
+	fmt.Fprintf(tw, "Cursors\t: %d\n", len(info.Cursors))
+
+	fmt.Fprintf(tw, "\nCursors (size (frames)):\n")
+
+	for _, shape := range info.Cursors {
+		switch {
+		case shape.Dangling:
+			fmt.Fprintf(tw, "\t%s\tdangling symlink to %s\n", shape.Name, shape.Target)
+		case shape.Error != "":
+			fmt.Fprintf(tw, "\t%s\tinvalid: %s\n", shape.Name, shape.Error)
+		case shape.Target != "":
+			fmt.Fprintf(tw, "\t%s\t%s -> %s\n", shape.Name, formatSizes(shape.Sizes), shape.Target)
+		default:
+			fmt.Fprintf(tw, "\t%s\t%s\n", shape.Name, formatSizes(shape.Sizes))
+		}
+	}
+
+	fmt.Fprintf(tw, "\nStandard cursors:\n")
+
+	for _, status := range info.Standard {
+		state := "ok"
+
+		switch {
+		case !status.Present:
+			state = "missing"
+		case len(status.MissingAliases) > 0:
+			state = "missing aliases: " + strings.Join(status.MissingAliases, ", ")
+		}
+
+		fmt.Fprintf(tw, "\t%s\t%s\t%s\n", status.Name, strings.Join(status.Aliases, ", "), state)
+	}
+
+	tw.Flush()
+
+	return nil
+}
+
+func formatCursorSize(size int) string {
+	if size <= 0 {
+		return "default"
+	}
+
+	return strconv.Itoa(size)
+}
+
+func cursorPreview(args []string) error {
+	fs := newFlagSet("cursor preview")
+
+	output := fs.String("o", "", "Output PNG file")
+	size := fs.Int("size", 24, "Cursor size")
+
+	positional, err := parseInterspersed(fs, args, printCursorPreviewHelp)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return fmt.Errorf("'cursor preview' requires exactly one cursor theme name")
+	}
+
+	if *output == "" {
+		return fmt.Errorf("please specify an output file with -o")
+	}
+
+	if *size <= 0 {
+		return fmt.Errorf("size must be positive")
+	}
+
+	if err := writeCursorPreview(positional[0], *size, *output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Wrote preview of '%s' to %s\n", positional[0], *output)
+
+	return nil
+}
+
+func cursorConvert(args []string) error {
+	fs := newFlagSet("cursor convert")
+
+	to := fs.String("to", "", "Target format")
+	outDir := fs.String("out", "", "Output directory")
+
+	positional, err := parseInterspersed(fs, args, printCursorConvertHelp)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return fmt.Errorf("'cursor convert' requires exactly one cursor theme name")
+	}
+
+	if *to != "hyprcursor" {
+		return fmt.Errorf("unsupported target format: '%s'. only 'hyprcursor' is supported", *to)
+	}
+
+	if *outDir == "" {
+		return fmt.Errorf("please specify an output directory with -out")
+	}
+
+	count, err := convertToHyprcursor(positional[0], *outDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Converted %d cursors of '%s' to %s\n", count, positional[0], *outDir)
+
+	return nil
+}
+
+func iconCache(opts globalOptions, args []string) error {
+	fs := newFlagSet("icon cache")
+
+	if err := parseFlag(fs, args, printIconCacheHelp); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		printIconCacheHelp(newTabWriter(os.Stderr))
+		return fmt.Errorf("'icon cache' requires a command and exactly one icon theme name")
+	}
+
+	themeName := fs.Arg(1)
+
+	switch fs.Arg(0) {
+	case "build":
+		path, count, err := buildIconCache(themeName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "Wrote %s (%d icons)\n", path, count)
+
+		return nil
+	case "verify":
+		status, err := verifyIconCache(themeName)
+		if err != nil {
+			return err
+		}
+
+		if opts.format != formatText {
+			if err := writeStructured(os.Stdout, opts.format, status); err != nil {
+				return err
+			}
+		} else {
+			tw := newTabWriter(os.Stdout)
+
+			fmt.Fprintf(tw, "Theme\t: %s\n", status.Theme)
+			fmt.Fprintf(tw, "Cache\t: %s\n", status.Path)
+
+			if status.Exists {
+				fmt.Fprintf(tw, "Icons\t: %d\n", status.Icons)
+				fmt.Fprintf(tw, "Directories\t: %d\n", status.Directories)
+			}
+
+			for _, dir := range status.Stale {
+				fmt.Fprintf(tw, "Modified after cache\t: %s\n", dir)
+			}
+
+			tw.Flush()
+		}
+
+		if len(status.Problems) > 0 {
+			return fmt.Errorf("icon cache of '%s' needs rebuilding: %s. run 'lookctl icon cache build %s'", themeName, strings.Join(status.Problems, "; "), themeName)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown icon cache command: '%s'. must be either 'build' or 'verify'", fs.Arg(0))
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	envHyprlandInstance = "HYPRLAND_INSTANCE_SIGNATURE"
+	envSwaySock         = "SWAYSOCK"
+)
+
+// defaultCursorSize is used where a compositor needs an explicit size and
+// none is configured.
+const defaultCursorSize = 24
+
+const snippetHeader = "# Managed by lookctl. Changes to this file will be overwritten.\n"
+
+// compositor describes a wlroots style compositor that ignores gsettings.
+// The snippet is a file lookctl owns inside the compositor's config
+// directory, which the main config has to pull in with an include
+// directive. command applies the cursor to a running session.
+type compositor struct {
+	name       string
+	env        string
+	configDir  string
+	snippet    string
+	mainConfig string
+	include    string
+	render     func(cfg themeConfig) string
+	command    func(cfg themeConfig) []string
+}
+
+var compositors = []compositor{
+	{
+		name:       "hyprland",
+		env:        envHyprlandInstance,
+		configDir:  "hypr",
+		snippet:    "lookctl.conf",
+		mainConfig: "hyprland.conf",
+		include:    "source",
+		render:     renderHyprlandSnippet,
+		command:    hyprlandCursorCommand,
+	},
+	{
+		name:       "sway",
+		env:        envSwaySock,
+		configDir:  "sway",
+		snippet:    filepath.Join("config.d", "lookctl"),
+		mainConfig: "config",
+		include:    "include",
+		render:     renderSwaySnippet,
+		command:    swayCursorCommand,
+	},
+}
+
+func getCursorSizeOrDefault(cfg themeConfig) int {
+	if cfg.cursorSize > 0 {
+		return cfg.cursorSize
+	}
+
+	return defaultCursorSize
+}
+
+func renderHyprlandSnippet(cfg themeConfig) string {
+	var b strings.Builder
+
+	b.WriteString(snippetHeader)
+	fmt.Fprintf(&b, "env = XCURSOR_THEME,%s\n", cfg.cursorTheme)
+	fmt.Fprintf(&b, "env = HYPRCURSOR_THEME,%s\n", cfg.cursorTheme)
+
+	if cfg.cursorSize > 0 {
+		fmt.Fprintf(&b, "env = XCURSOR_SIZE,%d\n", cfg.cursorSize)
+		fmt.Fprintf(&b, "env = HYPRCURSOR_SIZE,%d\n", cfg.cursorSize)
+	}
+
+	return b.String()
+}
+
+func hyprlandCursorCommand(cfg themeConfig) []string {
+	return []string{"hyprctl", "setcursor", cfg.cursorTheme, strconv.Itoa(getCursorSizeOrDefault(cfg))}
+}
+
+func renderSwaySnippet(cfg themeConfig) string {
+	line := "seat * xcursor_theme " + cfg.cursorTheme
+	if cfg.cursorSize > 0 {
+		line += " " + strconv.Itoa(cfg.cursorSize)
+	}
+
+	return snippetHeader + line + "\n"
+}
+
+func swayCursorCommand(cfg themeConfig) []string {
+	return []string{"swaymsg", "seat", "*", "xcursor_theme", cfg.cursorTheme, strconv.Itoa(getCursorSizeOrDefault(cfg))}
+}
+
+// compositorSteps returns the snippet of every compositor that has a config
+// directory, and the live cursor update of every compositor running in
+// this session.
+func compositorSteps(b backend, cfg themeConfig, run commandRunner) []applyStep {
+	steps := []applyStep{}
+
+	if cfg.cursorTheme == "" {
+		return steps
+	}
+
+	for _, c := range compositors {
+		configDir := filepath.Join(getConfigDir(), c.configDir)
+
+		if isDir(configDir) {
+			render := func(old []byte) []byte {
+				return []byte(c.render(cfg))
+			}
+
+			steps = append(steps, &snippetStep{
+				fileStep:  newFileStep(filepath.Join(c.configDir, c.snippet), filepath.Join(configDir, c.snippet), render),
+				c:         c,
+				configDir: configDir,
+			})
+		}
+
+		if os.Getenv(c.env) != "" {
+			steps = append(steps, &compositorStep{c: c, b: b, cfg: cfg, run: run})
+		}
+	}
+
+	return steps
+}
+
+// snippetStep writes a compositor snippet, remembering the compositor so
+// a snippet the main config does not include can be reported afterwards.
+type snippetStep struct {
+	*fileStep
+	c         compositor
+	configDir string
+}
+
+// warnSnippetsNotIncluded warns about every snippet a finished transaction
+// changed that the compositor will not read.
+func warnSnippetsNotIncluded(steps []applyStep) {
+	for _, step := range steps {
+		s, ok := step.(*snippetStep)
+		if !ok || !s.written {
+			continue
+		}
+
+		mainConfig := filepath.Join(s.configDir, s.c.mainConfig)
+
+		data, err := os.ReadFile(mainConfig)
+		if err != nil {
+			continue
+		}
+
+		if !isSnippetIncluded(s.c, data, s.configDir) {
+			fmt.Fprintf(os.Stderr, "warning: %s does not include %s; add it to apply the cursor on the next start\n", mainConfig, s.path)
+		}
+	}
+}
+
+// isSnippetIncluded reports whether an include directive of the main
+// config matches the snippet. Paths are expanded like the compositors do,
+// and relative paths are resolved against the config directory.
+func isSnippetIncluded(c compositor, mainConfig []byte, configDir string) bool {
+	snippetPath := filepath.Join(configDir, c.snippet)
+
+	for _, line := range strings.Split(string(mainConfig), "\n") {
+		line = strings.TrimSpace(line)
+
+		rest, ok := strings.CutPrefix(line, c.include)
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t' && rest[0] != '=') {
+			continue
+		}
+
+		if i := strings.Index(rest, " #"); i >= 0 {
+			rest = rest[:i]
+		}
+
+		rest = strings.TrimSpace(rest)
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "="))
+		rest = strings.Trim(rest, `"'`)
+
+		if rest == "" {
+			continue
+		}
+
+		pattern := expandConfigPath(rest)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(configDir, pattern)
+		}
+
+		if matched, err := filepath.Match(filepath.Clean(pattern), snippetPath); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandConfigPath expands a leading ~ and environment variables such as
+// $XDG_CONFIG_HOME in an include path.
+func expandConfigPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = os.Getenv(envHome) + path[1:]
+	}
+
+	return os.ExpandEnv(path)
+}
+
+// compositorStep applies the cursor to a running compositor. The previous
+// cursor is taken from the backend, since compositors cannot report it.
+type compositorStep struct {
+	c   compositor
+	b   backend
+	cfg themeConfig
+	run commandRunner
+
+	old     themeConfig
+	applied bool
+}
+
+func (s *compositorStep) describe() string {
+	return "apply cursor to " + s.c.name
+}
+
+func (s *compositorStep) snapshot() error {
+	old, err := s.b.read()
+	if err != nil {
+		return err
+	}
+
+	s.old = old
+
+	return nil
+}
+
+func (s *compositorStep) apply() error {
+	if s.cfg.cursorTheme == s.old.cursorTheme && s.cfg.cursorSize == s.old.cursorSize {
+		return nil
+	}
+
+	args := s.c.command(s.cfg)
+
+	if _, err := s.run(args[0], args[1:]...); err != nil {
+		return err
+	}
+
+	s.applied = true
+
+	return nil
+}
+
+func (s *compositorStep) restore() error {
+	if !s.applied || s.old.cursorTheme == "" {
+		return nil
+	}
+
+	args := s.c.command(s.old)
+
+	_, err := s.run(args[0], args[1:]...)
+
+	return err
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestCompositorSteps(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "config")
+
+	t.Setenv(envConfigHome, configDir)
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+	t.Setenv(envHyprlandInstance, "abc")
+	t.Setenv(envSwaySock, "")
+
+	test.CreateEmptyDir(t, filepath.Join(configDir, "hypr"))
+	test.CreateEmptyDir(t, filepath.Join(configDir, "sway"))
+
+	err := os.WriteFile(filepath.Join(configDir, "sway", "config"), []byte("include ~/.config/sway/config.d/*\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	runner := newFakeRunner(nil)
+	b := &fakeBackend{cfg: themeConfig{cursorTheme: "Adwaita"}}
+	cfg := themeConfig{cursorTheme: "Bibata", cursorSize: 32}
+
+	test.RequireNoError(t, runTransaction(compositorSteps(b, cfg, runner.run)))
+
+	hypr, err := os.ReadFile(filepath.Join(configDir, "hypr", "lookctl.conf"))
+	test.RequireNoError(t, err)
+
+	wantHypr := snippetHeader + "env = XCURSOR_THEME,Bibata\nenv = HYPRCURSOR_THEME,Bibata\nenv = XCURSOR_SIZE,32\nenv = HYPRCURSOR_SIZE,32\n"
+	if string(hypr) != wantHypr {
+		t.Errorf("got hyprland snippet %q; want %q", hypr, wantHypr)
+	}
+
+	sway, err := os.ReadFile(filepath.Join(configDir, "sway", "config.d", "lookctl"))
+	test.RequireNoError(t, err)
+
+	if string(sway) != snippetHeader+"seat * xcursor_theme Bibata 32\n" {
+		t.Errorf("got sway snippet %q", sway)
+	}
+
+	// only hyprland is running
+	if !slices.Equal(runner.calls, []string{"hyprctl setcursor Bibata 32"}) {
+		t.Errorf("got calls %q", runner.calls)
+	}
+}
+
+func TestCompositorStepRollback(t *testing.T) {
+	t.Setenv(envConfigHome, t.TempDir())
+	t.Setenv(envHyprlandInstance, "abc")
+	t.Setenv(envSwaySock, "sock")
+
+	runner := newFakeRunner(nil)
+	runner.fail["swaymsg seat * xcursor_theme Bibata 24"] = true
+
+	oldCfg := themeConfig{cursorTheme: "Adwaita"}
+	b := &fakeBackend{cfg: oldCfg}
+	cfg := themeConfig{cursorTheme: "Bibata"}
+
+	// the order saveCurrentTheme uses
+	steps := []applyStep{&backendStep{b: b, cfg: cfg}}
+	steps = append(steps, compositorSteps(b, cfg, runner.run)...)
+
+	if err := runTransaction(steps); err == nil {
+		t.Fatal("expected the failing compositor to abort the transaction")
+	}
+
+	want := []string{
+		"hyprctl setcursor Bibata 24",
+		"swaymsg seat * xcursor_theme Bibata 24",
+		"hyprctl setcursor Adwaita 24",
+	}
+
+	if !slices.Equal(runner.calls, want) {
+		t.Errorf("got calls %q; want %q", runner.calls, want)
+	}
+
+	if b.cfg != oldCfg {
+		t.Errorf("backend not restored: got %+v; want %+v", b.cfg, oldCfg)
+	}
+}
+
+func TestCompositorStepUnchangedCursor(t *testing.T) {
+	t.Setenv(envConfigHome, t.TempDir())
+	t.Setenv(envHyprlandInstance, "abc")
+	t.Setenv(envSwaySock, "")
+
+	runner := newFakeRunner(nil)
+	b := &fakeBackend{cfg: themeConfig{gtkTheme: "Adwaita", cursorTheme: "Bibata"}}
+
+	test.RequireNoError(t, runTransaction(compositorSteps(b, themeConfig{gtkTheme: "Nordic", cursorTheme: "Bibata"}, runner.run)))
+
+	if len(runner.calls) != 0 {
+		t.Errorf("got calls %q for an unchanged cursor", runner.calls)
+	}
+}
+
+func TestSnippetStepWritten(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "config")
+
+	t.Setenv(envConfigHome, configDir)
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+	t.Setenv(envHyprlandInstance, "")
+	t.Setenv(envSwaySock, "")
+
+	test.CreateEmptyDir(t, filepath.Join(configDir, "sway"))
+
+	b := &fakeBackend{}
+	cfg := themeConfig{cursorTheme: "Bibata"}
+
+	written := func() []bool {
+		steps := compositorSteps(b, cfg, newFakeRunner(nil).run)
+		test.RequireNoError(t, runTransaction(steps))
+
+		got := []bool{}
+		for _, step := range steps {
+			if s, ok := step.(*snippetStep); ok {
+				got = append(got, s.written)
+			}
+		}
+
+		return got
+	}
+
+	if got := written(); !slices.Equal(got, []bool{true}) {
+		t.Errorf("got written %v on the first run; want [true]", got)
+	}
+
+	if got := written(); !slices.Equal(got, []bool{false}) {
+		t.Errorf("got written %v for an unchanged snippet; want [false]", got)
+	}
+}
+
+func TestIsSnippetIncluded(t *testing.T) {
+	home := t.TempDir()
+	configHome := filepath.Join(home, ".config")
+
+	t.Setenv(envHome, home)
+	t.Setenv(envConfigHome, configHome)
+
+	hyprland, sway := compositors[0], compositors[1]
+
+	tests := []struct {
+		description string
+		c           compositor
+		config      string
+		want        bool
+	}{
+		{
+			description: "system include does not match",
+			c:           sway,
+			config:      "include /etc/sway/config.d/*\n",
+			want:        false,
+		},
+		{
+			description: "home glob matches",
+			c:           sway,
+			config:      "include /etc/sway/config.d/*\ninclude ~/.config/sway/config.d/*\n",
+			want:        true,
+		},
+		{
+			description: "config home variable is expanded",
+			c:           sway,
+			config:      "include $XDG_CONFIG_HOME/sway/config.d/lookctl\n",
+			want:        true,
+		},
+		{
+			description: "relative path is resolved against the config dir",
+			c:           sway,
+			config:      "include config.d/*\n",
+			want:        true,
+		},
+		{
+			description: "commented out include is ignored",
+			c:           sway,
+			config:      "# include ~/.config/sway/config.d/*\n",
+			want:        false,
+		},
+		{
+			description: "other directive mentioning the path is ignored",
+			c:           sway,
+			config:      "exec echo ~/.config/sway/config.d/*\n",
+			want:        false,
+		},
+		{
+			description: "hyprland source matches",
+			c:           hyprland,
+			config:      "source = ~/.config/hypr/lookctl.conf # cursor\n",
+			want:        true,
+		},
+		{
+			description: "hyprland source of another file does not match",
+			c:           hyprland,
+			config:      "source=~/.config/hypr/colors.conf\n",
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got := isSnippetIncluded(tt.c, []byte(tt.config), filepath.Join(configHome, tt.c.configDir))
+
+			if got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	previewColumns = 6
+	previewPadding = 8
+	glyphWidth     = 5
+	glyphHeight    = 7
+	glyphAdvance   = glyphWidth + 1
+)
+
+var (
+	previewBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	previewBackdrop   = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	previewText       = color.RGBA{0x20, 0x20, 0x20, 0xff}
+	previewMissing    = color.RGBA{0xc0, 0x20, 0x20, 0xff}
+)
+
+// previewFont is a 5x7 bitmap font covering the characters used in cursor
+// and theme names. Each row is stored in the low five bits, most
+// significant bit on the left. Lowercase letters are drawn as uppercase.
+var previewFont = map[rune][glyphHeight]uint8{
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D': {0b11110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b11110},
+	'E': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G': {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H': {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I': {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J': {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K': {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L': {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M': {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N': {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O': {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P': {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q': {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R': {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S': {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T': {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W': {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X': {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y': {0b10001, 0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100},
+	'Z': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'_': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b11111},
+	'-': {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'.': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	' ': {},
+	'?': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+}
+
+// rgba converts a frame to an image. Xcursor pixels are premultiplied
+// ARGB, which maps directly onto the premultiplied image.RGBA layout.
+func (img xcursorImage) rgba() *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, img.width, img.height))
+
+	for i, pixel := range img.pixels {
+		out.Pix[i*4] = uint8(pixel >> 16)
+		out.Pix[i*4+1] = uint8(pixel >> 8)
+		out.Pix[i*4+2] = uint8(pixel)
+		out.Pix[i*4+3] = uint8(pixel >> 24)
+	}
+
+	return out
+}
+
+// nearestSize picks the available size closest to want, preferring the
+// smaller one on ties, the same way libXcursor chooses a size.
+func nearestSize(sizes []int, want int) int {
+	best := 0
+
+	for _, size := range sizes {
+		if best == 0 || abs(size-want) < abs(best-want) {
+			best = size
+		}
+	}
+
+	return best
+}
+
+func textWidth(text string, scale int) int {
+	if text == "" {
+		return 0
+	}
+
+	return (len([]rune(text))*glyphAdvance - 1) * scale
+}
+
+func drawText(dst *image.RGBA, x, y int, text string, scale int, c color.Color) {
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := previewFont[r]
+		if !ok {
+			glyph = previewFont['?']
+		}
+
+		for row, bits := range glyph {
+			for col := range glyphWidth {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+
+				rect := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, rect, image.NewUniform(c), image.Point{}, draw.Src)
+			}
+		}
+
+		x += glyphAdvance * scale
+	}
+}
+
+// loadPreviewFrame returns the first frame of a standard cursor at the
+// size closest to the requested one, falling back to its aliases when the
+// theme does not ship the core X11 name.
+func loadPreviewFrame(cursorsDir, name string, aliases []string, size int) (xcursorImage, bool) {
+	for _, candidate := range append([]string{name}, aliases...) {
+		file, err := readXcursor(filepath.Join(cursorsDir, candidate))
+		if err != nil {
+			continue
+		}
+
+		frames := file.frames(nearestSize(file.sizes(), size))
+		if len(frames) > 0 {
+			return frames[0], true
+		}
+	}
+
+	return xcursorImage{}, false
+}
+
+// renderCursorPreview lays out the standard cursors of a theme on a grid,
+// each drawn over a neutral backdrop with its name underneath.
+func renderCursorPreview(name string, size int) (*image.RGBA, error) {
+	cursorsDir, err := findXcursorDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]xcursorImage, len(standardCursors))
+	found := make([]bool, len(standardCursors))
+
+	slot := size
+	labelWidth := 0
+
+	for i, standard := range standardCursors {
+		frames[i], found[i] = loadPreviewFrame(cursorsDir, standard.name, standard.aliases, size)
+
+		slot = max(slot, frames[i].width, frames[i].height)
+		labelWidth = max(labelWidth, textWidth(standard.name, 1))
+	}
+
+	if !slices.Contains(found, true) {
+		return nil, fmt.Errorf("cursor theme '%s' has no readable standard cursors", name)
+	}
+
+	cellWidth := max(slot, labelWidth) + previewPadding*2
+	cellHeight := slot + glyphHeight + previewPadding*3
+
+	title := name + " " + strconv.Itoa(size)
+	headerHeight := glyphHeight*2 + previewPadding*2
+
+	rows := (len(standardCursors) + previewColumns - 1) / previewColumns
+	width := max(cellWidth*previewColumns, textWidth(title, 2)+previewPadding*2)
+	height := headerHeight + cellHeight*rows
+
+	sheet := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(previewBackground), image.Point{}, draw.Src)
+
+	drawText(sheet, previewPadding, previewPadding, title, 2, previewText)
+
+	for i, standard := range standardCursors {
+		x := (i % previewColumns) * cellWidth
+		y := headerHeight + (i/previewColumns)*cellHeight
+
+		backdrop := image.Rect(x+(cellWidth-slot)/2, y+previewPadding, x+(cellWidth+slot)/2, y+previewPadding+slot)
+		draw.Draw(sheet, backdrop, image.NewUniform(previewBackdrop), image.Point{}, draw.Src)
+
+		labelColor := previewText
+
+		if found[i] {
+			frame := frames[i]
+			origin := backdrop.Min.Add(image.Pt((slot-frame.width)/2, (slot-frame.height)/2))
+
+			draw.Draw(sheet, image.Rectangle{origin, origin.Add(image.Pt(frame.width, frame.height))}, frame.rgba(), image.Point{}, draw.Over)
+		} else {
+			labelColor = previewMissing
+		}
+
+		labelX := x + (cellWidth-textWidth(standard.name, 1))/2
+		drawText(sheet, labelX, backdrop.Max.Y+previewPadding, standard.name, 1, labelColor)
+	}
+
+	return sheet, nil
+}
+
+func writeCursorPreview(name string, size int, output string) error {
+	sheet, err := renderCursorPreview(name, size)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sheet); err != nil {
+		return fmt.Errorf("failed to encode preview: %w", err)
+	}
+
+	if err := writeFileAtomic(output, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestXcursorImageRGBA(t *testing.T) {
+	img := xcursorImage{width: 2, height: 1, pixels: []uint32{0xff102030, 0x80400000}}
+
+	got := img.rgba()
+
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{0x10, 0x20, 0x30, 0xff}) {
+		t.Errorf("got %v at (0, 0)", c)
+	}
+
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{0x40, 0x00, 0x00, 0x80}) {
+		t.Errorf("got %v at (1, 0)", c)
+	}
+}
+
+func TestNearestSize(t *testing.T) {
+	sizes := []int{24, 32, 48}
+
+	for want, expected := range map[int]int{16: 24, 28: 24, 30: 32, 40: 32, 96: 48} {
+		if got := nearestSize(sizes, want); got != expected {
+			t.Errorf("nearestSize(%d) = %d; want %d", want, got, expected)
+		}
+	}
+}
+
+func TestWriteCursorPreview(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	themePath := filepath.Join(iconDirPath, "Bibata")
+	cursorsPath := filepath.Join(themePath, "cursors")
+
+	test.CreateEmptyDir(t, cursorsPath)
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	data := encodeTestXcursor([]xcursorImage{newTestXcursorImage(24, 0)})
+	test.RequireNoError(t, os.WriteFile(filepath.Join(cursorsPath, "left_ptr"), data, 0o644))
+
+	// only the alias is shipped for the text cursor
+	test.RequireNoError(t, os.WriteFile(filepath.Join(cursorsPath, "text"), data, 0o644))
+
+	output := filepath.Join(t.TempDir(), "sheet.png")
+	test.RequireNoError(t, writeCursorPreview("Bibata", 32, output))
+
+	f, err := os.Open(output)
+	test.RequireNoError(t, err)
+	defer f.Close()
+
+	sheet, err := png.Decode(f)
+	test.RequireNoError(t, err)
+
+	if bounds := sheet.Bounds(); bounds.Dx() < 32*previewColumns || bounds.Dy() < 32 {
+		t.Errorf("preview too small: %v", bounds)
+	}
+}
+
+func TestRenderCursorPreviewWithoutCursors(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	themePath := filepath.Join(iconDirPath, "Empty")
+
+	test.CreateEmptyDir(t, filepath.Join(themePath, "cursors"))
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	if _, err := renderCursorPreview("Empty", 24); err == nil {
+		t.Error("expected error for a theme without cursors")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// standardCursors maps the core X11 cursor names every theme is expected to
+// ship to the CSS cursor names toolkits request for the same shape.
+var standardCursors = []struct {
+	name    string
+	aliases []string
+}{
+	{"left_ptr", []string{"default", "arrow"}},
+	{"xterm", []string{"text"}},
+	{"hand2", []string{"pointer"}},
+	{"watch", []string{"wait"}},
+	{"left_ptr_watch", []string{"progress"}},
+	{"question_arrow", []string{"help"}},
+	{"crosshair", []string{"cross"}},
+	{"fleur", []string{"move", "all-scroll"}},
+	{"sb_h_double_arrow", []string{"ew-resize", "col-resize"}},
+	{"sb_v_double_arrow", []string{"ns-resize", "row-resize"}},
+	{"top_left_corner", []string{"nw-resize"}},
+	{"top_right_corner", []string{"ne-resize"}},
+	{"bottom_left_corner", []string{"sw-resize"}},
+	{"bottom_right_corner", []string{"se-resize"}},
+	{"top_side", []string{"n-resize"}},
+	{"bottom_side", []string{"s-resize"}},
+	{"left_side", []string{"w-resize"}},
+	{"right_side", []string{"e-resize"}},
+	{"crossed_circle", []string{"not-allowed"}},
+	{"plus", []string{"cell"}},
+	{"openhand", []string{"grab"}},
+	{"closedhand", []string{"grabbing"}},
+	{"X_cursor", []string{}},
+}
+
+type cursorSize struct {
+	Size   int `json:"size"`
+	Frames int `json:"frames"`
+}
+
+type cursorShape struct {
+	Name     string       `json:"name"`
+	Target   string       `json:"target,omitempty"`
+	Sizes    []cursorSize `json:"sizes"`
+	Dangling bool         `json:"dangling"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type standardCursorStatus struct {
+	Name           string   `json:"name"`
+	Present        bool     `json:"present"`
+	Aliases        []string `json:"aliases"`
+	MissingAliases []string `json:"missing_aliases"`
+}
+
+type cursorThemeInfo struct {
+	Theme    string                 `json:"theme"`
+	Path     string                 `json:"path"`
+	Sizes    []int                  `json:"sizes"`
+	Cursors  []cursorShape          `json:"cursors"`
+	Standard []standardCursorStatus `json:"standard"`
+}
+
+func findCursorThemePath(name string) (string, error) {
+	path := getAssetPath(findInstalledCursorThemes(), name)
+	if path == "" {
+		return "", fmt.Errorf("cursor theme '%s' not found. see 'lookctl list -cursor' for list available themes", name)
+	}
+
+	return path, nil
+}
+
+// findXcursorDir returns the Xcursor directory of an installed theme.
+// Themes that only ship hyprcursor files are listed too, but have none.
+func findXcursorDir(name string) (string, error) {
+	path, err := findCursorThemePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	cursorsDir := filepath.Join(path, "cursors")
+	if !isDir(cursorsDir) {
+		return "", fmt.Errorf("cursor theme '%s' has no Xcursor files; it only ships hyprcursor cursors", name)
+	}
+
+	return cursorsDir, nil
+}
+
+// getCursorThemeInfo parses every Xcursor file of an installed theme.
+// Symlinked aliases are reported with their target, and links pointing
+// nowhere are reported as dangling instead of failing the whole theme.
+func getCursorThemeInfo(name string) (cursorThemeInfo, error) {
+	cursorsDir, err := findXcursorDir(name)
+	if err != nil {
+		return cursorThemeInfo{}, err
+	}
+
+	path := filepath.Dir(cursorsDir)
+
+	entries, err := os.ReadDir(cursorsDir)
+	if err != nil {
+		return cursorThemeInfo{}, fmt.Errorf("failed to read cursors of '%s': %w", name, err)
+	}
+
+	info := cursorThemeInfo{
+		Theme:    name,
+		Path:     path,
+		Sizes:    []int{},
+		Cursors:  []cursorShape{},
+		Standard: []standardCursorStatus{},
+	}
+
+	present := map[string]bool{}
+
+	for _, entry := range entries {
+		shape := cursorShape{Name: entry.Name(), Sizes: []cursorSize{}}
+		cursorPath := filepath.Join(cursorsDir, entry.Name())
+
+		if entry.Type()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(cursorPath)
+			if err == nil {
+				shape.Target = target
+			}
+		}
+
+		file, err := readXcursor(cursorPath)
+
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			shape.Dangling = true
+		case err != nil:
+			shape.Error = err.Error()
+		default:
+			present[shape.Name] = true
+
+			for _, size := range file.sizes() {
+				shape.Sizes = append(shape.Sizes, cursorSize{Size: size, Frames: len(file.frames(size))})
+
+				if !slices.Contains(info.Sizes, size) {
+					info.Sizes = append(info.Sizes, size)
+				}
+			}
+		}
+
+		info.Cursors = append(info.Cursors, shape)
+	}
+
+	slices.Sort(info.Sizes)
+
+	for _, standard := range standardCursors {
+		status := standardCursorStatus{
+			Name:           standard.name,
+			Present:        present[standard.name],
+			Aliases:        standard.aliases,
+			MissingAliases: []string{},
+		}
+
+		for _, alias := range standard.aliases {
+			if !present[alias] {
+				status.MissingAliases = append(status.MissingAliases, alias)
+			}
+		}
+
+		info.Standard = append(info.Standard, status)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestGetCursorThemeInfo(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	themePath := filepath.Join(iconDirPath, "Bibata")
+	cursorsPath := filepath.Join(themePath, "cursors")
+
+	test.CreateEmptyDir(t, cursorsPath)
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	data := encodeTestXcursor([]xcursorImage{newTestXcursorImage(24, 0), newTestXcursorImage(48, 0)})
+
+	err := os.WriteFile(filepath.Join(cursorsPath, "left_ptr"), data, 0o644)
+	test.RequireNoError(t, err)
+
+	test.RequireNoError(t, os.Symlink("left_ptr", filepath.Join(cursorsPath, "default")))
+	test.RequireNoError(t, os.Symlink("nowhere", filepath.Join(cursorsPath, "xterm")))
+
+	got, err := getCursorThemeInfo("Bibata")
+	test.RequireNoError(t, err)
+
+	if !slices.Equal(got.Sizes, []int{24, 48}) {
+		t.Errorf("got sizes %v; want [24 48]", got.Sizes)
+	}
+
+	shapes := map[string]cursorShape{}
+	for _, shape := range got.Cursors {
+		shapes[shape.Name] = shape
+	}
+
+	if shapes["default"].Target != "left_ptr" || len(shapes["default"].Sizes) != 2 {
+		t.Errorf("alias not resolved: %+v", shapes["default"])
+	}
+
+	if !shapes["xterm"].Dangling {
+		t.Errorf("dangling symlink not detected: %+v", shapes["xterm"])
+	}
+
+	for _, status := range got.Standard {
+		switch status.Name {
+		case "left_ptr":
+			if !status.Present || !slices.Equal(status.MissingAliases, []string{"arrow"}) {
+				t.Errorf("got left_ptr status %+v", status)
+			}
+		case "xterm":
+			if status.Present {
+				t.Errorf("dangling xterm reported as present")
+			}
+		}
+	}
+}
+
+func TestHyprcursorOnlyTheme(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	t.Setenv(envConfigHome, t.TempDir())
+
+	themePath := filepath.Join(iconDirPath, "Hypr")
+	test.CreateEmptyDir(t, filepath.Join(themePath, hyprcursorDir))
+	test.CreateEmptyFile(t, filepath.Join(themePath, hyprcursorManifest))
+
+	if !slices.Contains(getInstalledCursorThemes(), "Hypr") {
+		t.Fatal("hyprcursor theme not listed")
+	}
+
+	opts := globalOptions{backend: "xsettingsd", format: formatText}
+
+	for _, tt := range []struct {
+		description string
+		err         error
+	}{
+		{"cursor info", cursorInfo(opts, []string{"Hypr"})},
+		{"set -cursor-size", set(opts, []string{"-cursor", "Hypr", "-cursor-size", "24"})},
+	} {
+		if tt.err == nil || !strings.Contains(tt.err.Error(), "has no Xcursor files") {
+			t.Errorf("%s: got %v; want a missing Xcursor files error", tt.description, tt.err)
+		}
+	}
+
+	if _, err := renderCursorPreview("Hypr", 24); err == nil || !strings.Contains(err.Error(), "has no Xcursor files") {
+		t.Errorf("preview: got %v; want a missing Xcursor files error", err)
+	}
+
+	if _, err := convertToHyprcursor("Hypr", t.TempDir()); err == nil || !strings.Contains(err.Error(), "has no Xcursor files") {
+		t.Errorf("convert: got %v; want a missing Xcursor files error", err)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// desktopEntry is a file in the freedesktop desktop entry format, which is
+// shared by index.theme and .desktop files.
+type desktopEntry struct {
+	groups []*desktopGroup
+}
+
+type desktopGroup struct {
+	name   string
+	keys   []string
+	values map[string]string
+}
+
+func readDesktopEntry(path string) (*desktopEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDesktopEntry(data), nil
+}
+
+// parseDesktopEntry is lenient: lines that are neither a group header nor
+// a key-value pair, and keys outside of any group, are ignored.
+func parseDesktopEntry(data []byte) *desktopEntry {
+	entry := &desktopEntry{}
+
+	var current *desktopGroup
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := line[1 : len(line)-1]
+
+			current = entry.group(name)
+			if current == nil {
+				current = &desktopGroup{name: name, values: map[string]string{}}
+				entry.groups = append(entry.groups, current)
+			}
+		case current != nil && strings.Contains(line, "="):
+			key, value, _ := strings.Cut(line, "=")
+			key = strings.TrimSpace(key)
+
+			if _, exists := current.values[key]; !exists {
+				current.keys = append(current.keys, key)
+			}
+
+			current.values[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return entry
+}
+
+func (d *desktopEntry) group(name string) *desktopGroup {
+	for _, g := range d.groups {
+		if g.name == name {
+			return g
+		}
+	}
+
+	return nil
+}
+
+// get returns the unescaped value of key. It is safe to call on a nil group.
+func (g *desktopGroup) get(key string) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+
+	value, ok := g.values[key]
+	if !ok {
+		return "", false
+	}
+
+	return unescapeDesktopValue(value), true
+}
+
+// getList splits a list value on sep. index.theme uses ',' for Inherits and
+// Directories while .desktop files use ';'.
+func (g *desktopGroup) getList(key string, sep byte) []string {
+	if g == nil {
+		return []string{}
+	}
+
+	value, ok := g.values[key]
+	if !ok {
+		return []string{}
+	}
+
+	items := []string{}
+
+	var sb strings.Builder
+
+	flush := func() {
+		if item := strings.TrimSpace(unescapeDesktopValue(sb.String())); item != "" {
+			items = append(items, item)
+		}
+
+		sb.Reset()
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == sep:
+			sb.WriteByte(sep)
+			i++
+		case value[i] == '\\' && i+1 < len(value):
+			sb.WriteByte(value[i])
+			sb.WriteByte(value[i+1])
+			i++
+		case value[i] == sep:
+			flush()
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	flush()
+
+	return items
+}
+
+// getLocalized returns every localized variant of key, such as Name[de],
+// keyed by locale.
+func (g *desktopGroup) getLocalized(key string) map[string]string {
+	localized := map[string]string{}
+
+	if g == nil {
+		return localized
+	}
+
+	for _, k := range g.keys {
+		locale, ok := strings.CutPrefix(k, key+"[")
+		if !ok || !strings.HasSuffix(locale, "]") {
+			continue
+		}
+
+		localized[strings.TrimSuffix(locale, "]")] = unescapeDesktopValue(g.values[k])
+	}
+
+	return localized
+}
+
+func unescapeDesktopValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 >= len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		i++
+
+		switch value[i] {
+		case 's':
+			sb.WriteByte(' ')
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	return sb.String()
+}
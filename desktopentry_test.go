@@ -0,0 +1,64 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+const sampleIndexTheme = `# comment
+[Icon Theme]
+Name=Papirus
+Name[de]=Papirus (Deutsch)
+Name[pt_BR]=Papirus\sBR
+Comment=Papirus icon theme
+Inherits=breeze,hicolor
+Directories=16x16/apps,scalable/apps, symbolic/apps
+
+[16x16/apps]
+Size=16
+Context=Applications
+Type=Fixed
+`
+
+func TestParseDesktopEntry(t *testing.T) {
+	entry := parseDesktopEntry([]byte(sampleIndexTheme))
+
+	group := entry.group("Icon Theme")
+	if group == nil {
+		t.Fatal("missing [Icon Theme] group")
+	}
+
+	name, ok := group.get("Name")
+	if !ok || name != "Papirus" {
+		t.Errorf("got Name %q, %t", name, ok)
+	}
+
+	localized := group.getLocalized("Name")
+	if len(localized) != 2 || localized["de"] != "Papirus (Deutsch)" || localized["pt_BR"] != "Papirus BR" {
+		t.Errorf("got localized names %v", localized)
+	}
+
+	test.AssertStringSlicesEqual(t, group.getList("Inherits", ','), []string{"breeze", "hicolor"})
+	test.AssertStringSlicesEqual(t, group.getList("Directories", ','), []string{"16x16/apps", "scalable/apps", "symbolic/apps"})
+
+	size, _ := entry.group("16x16/apps").get("Size")
+	if size != "16" {
+		t.Errorf("got Size %q; want %q", size, "16")
+	}
+
+	if _, ok := entry.group("missing").get("Name"); ok {
+		t.Error("expected lookup on missing group to fail")
+	}
+}
+
+func TestDesktopGroupGetListEscapedSeparator(t *testing.T) {
+	entry := parseDesktopEntry([]byte("[Desktop Entry]\nKeywords=a\\;b;c;;\n"))
+
+	got := entry.group("Desktop Entry").getList("Keywords", ';')
+
+	if !slices.Equal(got, []string{"a;b", "c"}) {
+		t.Errorf("got %q", got)
+	}
+}
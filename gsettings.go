@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	gnomeDesktopInterface    = "org.gnome.desktop.interface"
+	cinnamonDesktopInterface = "org.cinnamon.desktop.interface"
+	mateInterface            = "org.mate.interface"
+	matePeripheralsMouse     = "org.mate.peripherals-mouse"
+)
+
+// gsettingsKey is a key of a gsettings schema. The zero value means the
+// desktop has no equivalent setting.
+type gsettingsKey struct {
+	schema string
+	key    string
+}
+
+// gsettingsSchema maps the look settings onto the schemas of one desktop.
+// shellTheme is set to the gtk theme when the theme ships a shellDir.
+type gsettingsSchema struct {
+	name        string
+	gtkTheme    gsettingsKey
+	iconTheme   gsettingsKey
+	cursorTheme gsettingsKey
+	cursorSize  gsettingsKey
+	colorScheme gsettingsKey
+	shellTheme  gsettingsKey
+	shellDir    string
+}
+
+var gnomeSchema = gsettingsSchema{
+	name:        "gsettings",
+	gtkTheme:    gsettingsKey{gnomeDesktopInterface, "gtk-theme"},
+	iconTheme:   gsettingsKey{gnomeDesktopInterface, "icon-theme"},
+	cursorTheme: gsettingsKey{gnomeDesktopInterface, "cursor-theme"},
+	cursorSize:  gsettingsKey{gnomeDesktopInterface, "cursor-size"},
+	colorScheme: gsettingsKey{gnomeDesktopInterface, "color-scheme"},
+}
+
+var cinnamonSchema = gsettingsSchema{
+	name:        "cinnamon",
+	gtkTheme:    gsettingsKey{cinnamonDesktopInterface, "gtk-theme"},
+	iconTheme:   gsettingsKey{cinnamonDesktopInterface, "icon-theme"},
+	cursorTheme: gsettingsKey{cinnamonDesktopInterface, "cursor-theme"},
+	cursorSize:  gsettingsKey{cinnamonDesktopInterface, "cursor-size"},
+	colorScheme: gsettingsKey{"org.x.apps.portal", "color-scheme"},
+	shellTheme:  gsettingsKey{"org.cinnamon.theme", "name"},
+	shellDir:    "cinnamon",
+}
+
+var mateSchema = gsettingsSchema{
+	name:        "mate",
+	gtkTheme:    gsettingsKey{mateInterface, "gtk-theme"},
+	iconTheme:   gsettingsKey{mateInterface, "icon-theme"},
+	cursorTheme: gsettingsKey{matePeripheralsMouse, "cursor-theme"},
+	cursorSize:  gsettingsKey{matePeripheralsMouse, "cursor-size"},
+}
+
+type gsettingsBackend struct {
+	run    commandRunner
+	schema gsettingsSchema
+}
+
+func newGsettingsBackend() backend {
+	return &gsettingsBackend{run: runCommand, schema: gnomeSchema}
+}
+
+func newCinnamonBackend() backend {
+	return &gsettingsBackend{run: runCommand, schema: cinnamonSchema}
+}
+
+func newMateBackend() backend {
+	return &gsettingsBackend{run: runCommand, schema: mateSchema}
+}
+
+func (g *gsettingsBackend) name() string {
+	return g.schema.name
+}
+
+// available also requires the desktop's schema to be installed, since
+// gsettings itself is present on most systems.
+func (g *gsettingsBackend) available() bool {
+	if !commandExists("gsettings") {
+		return false
+	}
+
+	_, err := g.run("gsettings", "list-keys", g.schema.gtkTheme.schema)
+
+	return err == nil
+}
+
+func (g *gsettingsBackend) capabilities() capability {
+	caps := capability(0)
+
+	for _, k := range []struct {
+		cap capability
+		key gsettingsKey
+	}{
+		{capGtkTheme, g.schema.gtkTheme},
+		{capIconTheme, g.schema.iconTheme},
+		{capCursorTheme, g.schema.cursorTheme},
+		{capCursorSize, g.schema.cursorSize},
+		{capColorScheme, g.schema.colorScheme},
+	} {
+		if k.key.schema != "" {
+			caps |= k.cap
+		}
+	}
+
+	return caps
+}
+
+func (g *gsettingsBackend) read() (themeConfig, error) {
+	gtkTheme, err := g.get(g.schema.gtkTheme)
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read gtk theme information: %w", err)
+	}
+
+	iconTheme, err := g.get(g.schema.iconTheme)
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read icon theme information: %w", err)
+	}
+
+	cursorTheme, err := g.get(g.schema.cursorTheme)
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read cursor theme information: %w", err)
+	}
+
+	cursorSize, err := g.get(g.schema.cursorSize)
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read cursor size information: %w", err)
+	}
+
+	colorScheme, err := g.get(g.schema.colorScheme)
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read color scheme information: %w", err)
+	}
+
+	preferDark := false
+	if colorScheme == "prefer-dark" {
+		preferDark = true
+	}
+
+	return themeConfig{
+		gtkTheme:    gtkTheme,
+		iconTheme:   iconTheme,
+		cursorTheme: cursorTheme,
+		cursorSize:  parseGsettingsInt(cursorSize),
+		preferDark:  preferDark,
+	}, nil
+}
+
+func (g *gsettingsBackend) write(cfg themeConfig) error {
+	colorScheme := "prefer-light"
+
+	if cfg.preferDark {
+		colorScheme = "prefer-dark"
+	}
+
+	if err := g.set(g.schema.gtkTheme, cfg.gtkTheme); err != nil {
+		return fmt.Errorf("failed to set gtk theme: %w", err)
+	}
+
+	if g.schema.shellTheme.schema != "" {
+		if path := getAssetPath(findInstalledThemes(), cfg.gtkTheme); path != "" && isDir(filepath.Join(path, g.schema.shellDir)) {
+			if err := g.set(g.schema.shellTheme, cfg.gtkTheme); err != nil {
+				return fmt.Errorf("failed to set shell theme: %w", err)
+			}
+		}
+	}
+
+	if err := g.set(g.schema.iconTheme, cfg.iconTheme); err != nil {
+		return fmt.Errorf("failed to set icon theme: %w", err)
+	}
+
+	if err := g.set(g.schema.cursorTheme, cfg.cursorTheme); err != nil {
+		return fmt.Errorf("failed to set cursor theme: %w", err)
+	}
+
+	// a size of 0 is the default, so undoing a size change resets the key
+	if cfg.cursorSize > 0 {
+		if err := g.set(g.schema.cursorSize, strconv.Itoa(cfg.cursorSize)); err != nil {
+			return fmt.Errorf("failed to set cursor size: %w", err)
+		}
+	} else if err := g.reset(g.schema.cursorSize); err != nil {
+		return fmt.Errorf("failed to reset cursor size: %w", err)
+	}
+
+	if err := g.set(g.schema.colorScheme, colorScheme); err != nil {
+		return fmt.Errorf("failed to set color scheme: %w", err)
+	}
+
+	return nil
+}
+
+// get returns "" without running gsettings for keys the desktop lacks.
+func (g *gsettingsBackend) get(k gsettingsKey) (string, error) {
+	if k.schema == "" {
+		return "", nil
+	}
+
+	out, err := g.run("gsettings", "get", k.schema, k.key)
+	if err != nil {
+		return "", err
+	}
+
+	outStr := strings.TrimSpace(string(out))
+
+	outStr = strings.Trim(outStr, "'")
+
+	return outStr, nil
+}
+
+func (g *gsettingsBackend) set(k gsettingsKey, value string) error {
+	if k.schema == "" {
+		return nil
+	}
+
+	if _, err := g.run("gsettings", "set", k.schema, k.key, value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *gsettingsBackend) reset(k gsettingsKey) error {
+	if k.schema == "" {
+		return nil
+	}
+
+	if _, err := g.run("gsettings", "reset", k.schema, k.key); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseGsettingsInt parses integer values, which gsettings prints with a
+// type prefix such as "int32 24" unless the schema type is unambiguous.
+func parseGsettingsInt(value string) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+
+	n, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const envGtk2RcFiles = "GTK2_RC_FILES"
+
+type gtkrcTokenKind int
+
+const (
+	gtkrcSpace gtkrcTokenKind = iota
+	gtkrcComment
+	gtkrcIdent
+	gtkrcString
+	gtkrcNumber
+	gtkrcPunct
+)
+
+type gtkrcToken struct {
+	kind gtkrcTokenKind
+	text string
+}
+
+// gtkrcFile is a lossless token stream of a gtkrc file: concatenating the
+// token texts yields the original source. Only top level assignments are
+// ever rewritten, so includes, style blocks and comments are kept as-is.
+type gtkrcFile struct {
+	tokens []gtkrcToken
+}
+
+func parseGtkrc(data []byte) *gtkrcFile {
+	return &gtkrcFile{tokens: tokenizeGtkrc(string(data))}
+}
+
+func tokenizeGtkrc(src string) []gtkrcToken {
+	tokens := []gtkrcToken{}
+
+	for i := 0; i < len(src); {
+		start := i
+		kind := gtkrcPunct
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			kind = gtkrcSpace
+			for i < len(src) && strings.IndexByte(" \t\n\r", src[i]) >= 0 {
+				i++
+			}
+		case c == '#':
+			kind = gtkrcComment
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			kind = gtkrcComment
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 4
+			}
+		case c == '"' || c == '\'':
+			kind = gtkrcString
+			i++
+			for i < len(src) && src[i] != c {
+				if src[i] == '\\' && c == '"' {
+					i++
+				}
+				i++
+			}
+			i = min(i+1, len(src))
+		case isGtkrcDigit(c):
+			kind = gtkrcNumber
+			for i < len(src) && (isGtkrcDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+		case isGtkrcIdentStart(c):
+			kind = gtkrcIdent
+			for i < len(src) && (isGtkrcIdentStart(src[i]) || isGtkrcDigit(src[i]) || src[i] == '-') {
+				i++
+			}
+		default:
+			i++
+		}
+
+		tokens = append(tokens, gtkrcToken{kind: kind, text: src[start:i]})
+	}
+
+	return tokens
+}
+
+func isGtkrcDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isGtkrcIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// assignments returns the token index of the value of every top level
+// `key = value` statement.
+func (f *gtkrcFile) assignments(key string) []int {
+	indexes := []int{}
+	depth := 0
+
+	for i, tok := range f.tokens {
+		if tok.kind == gtkrcPunct {
+			switch tok.text {
+			case "{":
+				depth++
+			case "}":
+				depth = max(depth-1, 0)
+			}
+
+			continue
+		}
+
+		if depth != 0 || tok.kind != gtkrcIdent || tok.text != key {
+			continue
+		}
+
+		eq := f.nextSignificant(i)
+		if eq < 0 || f.tokens[eq].kind != gtkrcPunct || f.tokens[eq].text != "=" {
+			continue
+		}
+
+		value := f.nextSignificant(eq)
+		if value < 0 {
+			continue
+		}
+
+		switch f.tokens[value].kind {
+		case gtkrcString, gtkrcNumber, gtkrcIdent:
+			indexes = append(indexes, value)
+		}
+	}
+
+	return indexes
+}
+
+func (f *gtkrcFile) nextSignificant(i int) int {
+	for j := i + 1; j < len(f.tokens); j++ {
+		if f.tokens[j].kind != gtkrcSpace && f.tokens[j].kind != gtkrcComment {
+			return j
+		}
+	}
+
+	return -1
+}
+
+func (f *gtkrcFile) setString(key, value string) {
+	f.set(key, gtkrcToken{kind: gtkrcString, text: quoteGtkrcString(value)})
+}
+
+func (f *gtkrcFile) set(key string, value gtkrcToken) {
+	indexes := f.assignments(key)
+
+	for _, i := range indexes {
+		f.tokens[i] = value
+	}
+
+	if len(indexes) > 0 {
+		return
+	}
+
+	if n := len(f.tokens); n > 0 && !strings.HasSuffix(f.tokens[n-1].text, "\n") {
+		f.tokens = append(f.tokens, gtkrcToken{kind: gtkrcSpace, text: "\n"})
+	}
+
+	f.tokens = append(f.tokens,
+		gtkrcToken{kind: gtkrcIdent, text: key},
+		gtkrcToken{kind: gtkrcPunct, text: "="},
+		value,
+		gtkrcToken{kind: gtkrcSpace, text: "\n"},
+	)
+}
+
+func (f *gtkrcFile) get(key string) (string, bool) {
+	indexes := f.assignments(key)
+	if len(indexes) == 0 {
+		return "", false
+	}
+
+	tok := f.tokens[indexes[len(indexes)-1]]
+	if tok.kind != gtkrcString {
+		return tok.text, true
+	}
+
+	return unquoteGtkrcString(tok.text), true
+}
+
+func (f *gtkrcFile) bytes() []byte {
+	var sb strings.Builder
+
+	for _, tok := range f.tokens {
+		sb.WriteString(tok.text)
+	}
+
+	return []byte(sb.String())
+}
+
+func quoteGtkrcString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+
+	return `"` + s + `"`
+}
+
+func unquoteGtkrcString(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+
+	quote := s[0]
+	s = strings.TrimSuffix(s[1:], string(quote))
+
+	if quote == '\'' {
+		return s
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+
+		sb.WriteByte(s[i])
+	}
+
+	return sb.String()
+}
+
+// getGtkrc2Path returns the gtkrc file lookctl should edit. GTK 2 reads
+// every file in GTK2_RC_FILES in order with later files taking precedence,
+// so the last entry is the one that wins.
+func getGtkrc2Path() string {
+	rcFiles := strings.Split(os.Getenv(envGtk2RcFiles), ":")
+
+	for i := len(rcFiles) - 1; i >= 0; i-- {
+		if rcFiles[i] != "" {
+			return rcFiles[i]
+		}
+	}
+
+	return filepath.Join(os.Getenv(envHome), ".gtkrc-2.0")
+}
+
+// renderGtkrc updates the assignments owned by lookctl in a gtkrc file,
+// leaving everything else in the file untouched.
+func renderGtkrc(old []byte, cfg themeConfig) []byte {
+	rc := parseGtkrc(old)
+
+	rc.setString("gtk-theme-name", cfg.gtkTheme)
+	rc.setString("gtk-icon-theme-name", cfg.iconTheme)
+	rc.setString("gtk-cursor-theme-name", cfg.cursorTheme)
+
+	if cfg.cursorSize > 0 {
+		rc.set("gtk-cursor-theme-size", gtkrcToken{kind: gtkrcNumber, text: strconv.Itoa(cfg.cursorSize)})
+	}
+
+	return rc.bytes()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderGtkrc(t *testing.T) {
+	cfg := themeConfig{
+		gtkTheme:    "Adwaita",
+		iconTheme:   "Papirus",
+		cursorTheme: "Bibata",
+	}
+
+	tests := []struct {
+		description string
+		input       string
+		want        string
+	}{
+		{
+			description: "creates assignments in empty file",
+			input:       "",
+			want:        "gtk-theme-name=\"Adwaita\"\ngtk-icon-theme-name=\"Papirus\"\ngtk-cursor-theme-name=\"Bibata\"\n",
+		},
+		{
+			description: "updates assignments in place",
+			input:       "include \"/usr/share/themes/Foo/gtk-2.0/gtkrc\"\ngtk-theme-name = \"Old\" # old theme\ngtk-font-name = \"Sans 10\"\ngtk-icon-theme-name=\"Old\"\ngtk-cursor-theme-name='Old'\n",
+			want:        "include \"/usr/share/themes/Foo/gtk-2.0/gtkrc\"\ngtk-theme-name = \"Adwaita\" # old theme\ngtk-font-name = \"Sans 10\"\ngtk-icon-theme-name=\"Papirus\"\ngtk-cursor-theme-name=\"Bibata\"\n",
+		},
+		{
+			description: "ignores assignments inside style blocks",
+			input:       "style \"x\" {\n  gtk-theme-name = \"Nested\"\n}\nclass \"*\" style \"x\"",
+			want:        "style \"x\" {\n  gtk-theme-name = \"Nested\"\n}\nclass \"*\" style \"x\"\ngtk-theme-name=\"Adwaita\"\ngtk-icon-theme-name=\"Papirus\"\ngtk-cursor-theme-name=\"Bibata\"\n",
+		},
+		{
+			description: "ignores commented out assignments",
+			input:       "# gtk-theme-name = \"Commented\"\n/* gtk-icon-theme-name = \"Block\" */\n",
+			want:        "# gtk-theme-name = \"Commented\"\n/* gtk-icon-theme-name = \"Block\" */\ngtk-theme-name=\"Adwaita\"\ngtk-icon-theme-name=\"Papirus\"\ngtk-cursor-theme-name=\"Bibata\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got := string(renderGtkrc([]byte(tt.input), cfg))
+
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGtkrcRoundTrip(t *testing.T) {
+	input := "# comment\ninclude \"other\"\nstyle \"a\" { bg[NORMAL] = \"#fff\" }\ngtk-key-theme-name = \"Emacs\"\ngtk-toolbar-style = 3\n"
+
+	got := string(parseGtkrc([]byte(input)).bytes())
+
+	if got != input {
+		t.Errorf("got %q; want %q", got, input)
+	}
+}
+
+func TestGtkrcGetEscapedString(t *testing.T) {
+	rc := parseGtkrc(nil)
+	rc.setString("gtk-theme-name", `My "Quoted" \ Theme`)
+
+	got, ok := parseGtkrc(rc.bytes()).get("gtk-theme-name")
+	if !ok || got != `My "Quoted" \ Theme` {
+		t.Errorf("got %q, %t", got, ok)
+	}
+}
+
+func TestGetGtkrc2Path(t *testing.T) {
+	tests := []struct {
+		description string
+		rcFiles     string
+		want        string
+	}{
+		{
+			description: "defaults to gtkrc-2.0 in home",
+			rcFiles:     "",
+			want:        filepath.Join("/home/test", ".gtkrc-2.0"),
+		},
+		{
+			description: "uses the last entry of GTK2_RC_FILES",
+			rcFiles:     "/etc/gtk-2.0/gtkrc:/home/test/.config/gtk-2.0/gtkrc",
+			want:        "/home/test/.config/gtk-2.0/gtkrc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			t.Setenv(envHome, "/home/test")
+			t.Setenv(envGtk2RcFiles, tt.rcFiles)
+
+			got := getGtkrc2Path()
+
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
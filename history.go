@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	historySet  = "set"
+	historyUndo = "undo"
+	historyRedo = "redo"
+)
+
+type historyEntry struct {
+	Time   time.Time   `json:"time"`
+	Action string      `json:"action"`
+	Before themeRecord `json:"before"`
+	After  themeRecord `json:"after"`
+}
+
+func getHistoryPath() string {
+	return filepath.Join(getStateDir(), "lookctl", "history.jsonl")
+}
+
+// applyTheme saves cfg and records the change in the history log. A failure
+// to write the log does not undo an otherwise successful change.
+func applyTheme(b backend, before, after themeConfig, action string) error {
+	if err := saveCurrentTheme(b, after); err != nil {
+		return err
+	}
+
+	entry := historyEntry{
+		Time:   time.Now(),
+		Action: action,
+		Before: before.record(),
+		After:  after.record(),
+	}
+
+	if err := appendHistory(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not record history: %s\n", err)
+	}
+
+	return nil
+}
+
+func readHistory() ([]historyEntry, error) {
+	data, err := os.ReadFile(getHistoryPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	entries := []historyEntry{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse history line %d: %w", line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return entries, nil
+}
+
+func appendHistory(entry historyEntry) error {
+	path := getHistoryPath()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// getHistoryStacks replays the log to find which changes can be undone and
+// redone. Every action other than undo and redo starts a new branch, which
+// drops whatever was left to redo.
+func getHistoryStacks(entries []historyEntry) (undoStack, redoStack []historyEntry) {
+	for _, entry := range entries {
+		switch entry.Action {
+		case historyUndo:
+			if len(undoStack) > 0 {
+				redoStack = append(redoStack, undoStack[len(undoStack)-1])
+				undoStack = undoStack[:len(undoStack)-1]
+			}
+		case historyRedo:
+			if len(redoStack) > 0 {
+				undoStack = append(undoStack, redoStack[len(redoStack)-1])
+				redoStack = redoStack[:len(redoStack)-1]
+			}
+		default:
+			undoStack = append(undoStack, entry)
+			redoStack = nil
+		}
+	}
+
+	return undoStack, redoStack
+}
+
+func (e historyEntry) summary() string {
+	changes := []string{}
+
+	diff := func(what, before, after string) {
+		if before != after {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", what, before, after))
+		}
+	}
+
+	diff("gtk", e.Before.GTK, e.After.GTK)
+	diff("icon", e.Before.Icon, e.After.Icon)
+	diff("cursor", e.Before.Cursor, e.After.Cursor)
+	diff("cursor size", strconv.Itoa(e.Before.CursorSize), strconv.Itoa(e.After.CursorSize))
+	diff("color scheme", e.Before.ColorScheme, e.After.ColorScheme)
+
+	if len(changes) == 0 {
+		return "no changes"
+	}
+
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestGetHistoryStacks(t *testing.T) {
+	set := func(before, after string) historyEntry {
+		return historyEntry{Action: historySet, Before: themeRecord{GTK: before}, After: themeRecord{GTK: after}}
+	}
+
+	tests := []struct {
+		description string
+		entries     []historyEntry
+		wantUndo    []string
+		wantRedo    []string
+	}{
+		{
+			description: "sets are undoable",
+			entries:     []historyEntry{set("A", "B"), set("B", "C")},
+			wantUndo:    []string{"B", "C"},
+			wantRedo:    []string{},
+		},
+		{
+			description: "undo moves the last change to redo",
+			entries:     []historyEntry{set("A", "B"), set("B", "C"), {Action: historyUndo}},
+			wantUndo:    []string{"B"},
+			wantRedo:    []string{"C"},
+		},
+		{
+			description: "repeated undo keeps the stacks in order",
+			entries:     []historyEntry{set("A", "B"), set("B", "C"), set("C", "D"), {Action: historyUndo}, {Action: historyUndo}},
+			wantUndo:    []string{"B"},
+			wantRedo:    []string{"D", "C"},
+		},
+		{
+			description: "redo moves the change back",
+			entries:     []historyEntry{set("A", "B"), {Action: historyUndo}, {Action: historyRedo}},
+			wantUndo:    []string{"B"},
+			wantRedo:    []string{},
+		},
+		{
+			description: "new change clears redo",
+			entries:     []historyEntry{set("A", "B"), {Action: historyUndo}, set("A", "D")},
+			wantUndo:    []string{"D"},
+			wantRedo:    []string{},
+		},
+		{
+			description: "undo past the beginning is ignored",
+			entries:     []historyEntry{{Action: historyUndo}, set("A", "B")},
+			wantUndo:    []string{"B"},
+			wantRedo:    []string{},
+		},
+	}
+
+	afterNames := func(entries []historyEntry) []string {
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.After.GTK)
+		}
+
+		return names
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			undoStack, redoStack := getHistoryStacks(tt.entries)
+
+			if got := afterNames(undoStack); !slices.Equal(got, tt.wantUndo) {
+				t.Errorf("got undo stack %q; want %q", got, tt.wantUndo)
+			}
+
+			if got := afterNames(redoStack); !slices.Equal(got, tt.wantRedo) {
+				t.Errorf("got redo stack %q; want %q", got, tt.wantRedo)
+			}
+		})
+	}
+}
+
+func TestApplyThemeRecordsHistory(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Setenv(envHome, tempDir)
+	t.Setenv(envConfigHome, filepath.Join(tempDir, "config"))
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+	t.Setenv(envGtk2RcFiles, "")
+
+	before := themeConfig{gtkTheme: "Old"}
+	after := themeConfig{gtkTheme: "New", preferDark: true}
+
+	err := applyTheme(&fakeBackend{cfg: before}, before, after, historySet)
+	test.RequireNoError(t, err)
+
+	entries, err := readHistory()
+	test.RequireNoError(t, err)
+
+	if len(entries) != 1 {
+		t.Fatalf("got %d history entries; want 1", len(entries))
+	}
+
+	if entries[0].Before.config() != before || entries[0].After.config() != after {
+		t.Errorf("got entry %+v", entries[0])
+	}
+}
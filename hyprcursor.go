@@ -0,0 +1,215 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	hyprcursorManifest = "manifest.hl"
+	hyprcursorMeta     = "meta.hl"
+	hyprcursorDir      = "hyprcursors"
+)
+
+// hyprcursorShape is a single cursor of a hyprcursor theme. overrides are
+// the other names the shape answers to, taken from Xcursor symlinks.
+type hyprcursorShape struct {
+	name      string
+	overrides []string
+	images    []xcursorImage
+}
+
+func isHyprcursorTheme(path string) bool {
+	return isFile(filepath.Join(path, hyprcursorManifest)) || isFile(filepath.Join(path, "manifest.toml"))
+}
+
+// getHyprcursorShapes groups the Xcursor files of a theme into shapes.
+// Symlinks become overrides of the file they resolve to; dangling links
+// and links leaving the cursors directory are skipped.
+func getHyprcursorShapes(cursorsDir string) ([]hyprcursorShape, error) {
+	entries, err := os.ReadDir(cursorsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedDir, err := filepath.EvalSymlinks(cursorsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	shapes := []hyprcursorShape{}
+	index := map[string]int{}
+	aliases := map[string][]string{}
+
+	for _, entry := range entries {
+		path := filepath.Join(cursorsDir, entry.Name())
+
+		if entry.Type()&os.ModeSymlink != 0 {
+			resolved, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				continue
+			}
+
+			if filepath.Dir(resolved) != resolvedDir {
+				continue
+			}
+
+			target := filepath.Base(resolved)
+			aliases[target] = append(aliases[target], entry.Name())
+
+			continue
+		}
+
+		file, err := readXcursor(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s: %s\n", path, err)
+
+			continue
+		}
+
+		index[entry.Name()] = len(shapes)
+		shapes = append(shapes, hyprcursorShape{name: entry.Name(), overrides: []string{}, images: file.images})
+	}
+
+	for target, names := range aliases {
+		i, ok := index[target]
+		if !ok {
+			continue
+		}
+
+		slices.Sort(names)
+		shapes[i].overrides = names
+	}
+
+	return shapes, nil
+}
+
+// renderHyprcursorMeta writes the meta.hl of a shape. Hyprcursor only has a
+// single hotspot per shape, so it is taken from the largest image.
+func renderHyprcursorMeta(shape hyprcursorShape, files []string) []byte {
+	var buf bytes.Buffer
+
+	largest := shape.images[0]
+	for _, img := range shape.images {
+		if img.size > largest.size {
+			largest = img
+		}
+	}
+
+	hotspot := func(hot, length int) string {
+		if length == 0 {
+			return "0"
+		}
+
+		return strconv.FormatFloat(float64(hot)/float64(length), 'f', -1, 64)
+	}
+
+	fmt.Fprintf(&buf, "resize_algorithm = bilinear\n")
+	fmt.Fprintf(&buf, "hotspot_x = %s\n", hotspot(largest.xhot, largest.width))
+	fmt.Fprintf(&buf, "hotspot_y = %s\n", hotspot(largest.yhot, largest.height))
+
+	if len(shape.overrides) > 0 {
+		fmt.Fprintf(&buf, "\ndefine_override = %s\n", strings.Join(shape.overrides, ";"))
+	}
+
+	fmt.Fprintln(&buf)
+
+	for i, img := range shape.images {
+		if len(shape.images) > 1 && img.delay > 0 {
+			fmt.Fprintf(&buf, "define_size = %d, %s, %d\n", img.size, files[i], img.delay)
+		} else {
+			fmt.Fprintf(&buf, "define_size = %d, %s\n", img.size, files[i])
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// encodeHyprcursorShape packs a shape into the .hlc archive hyprcursor
+// loads: a zip holding meta.hl and one PNG per Xcursor image.
+func encodeHyprcursorShape(shape hyprcursorShape) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []string{}
+	frames := map[int]int{}
+
+	for _, img := range shape.images {
+		file := fmt.Sprintf("%s_%d_%d.png", shape.name, img.size, frames[img.size])
+		frames[img.size]++
+
+		w, err := archive.Create(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := png.Encode(w, img.rgba()); err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	w, err := archive.Create(hyprcursorMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(renderHyprcursorMeta(shape, files)); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// convertToHyprcursor writes an Xcursor theme as a hyprcursor theme in
+// outDir and returns the number of converted shapes.
+func convertToHyprcursor(name, outDir string) (int, error) {
+	cursorsDir, err := findXcursorDir(name)
+	if err != nil {
+		return 0, err
+	}
+
+	shapes, err := getHyprcursorShapes(cursorsDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cursors of '%s': %w", name, err)
+	}
+
+	if len(shapes) == 0 {
+		return 0, fmt.Errorf("cursor theme '%s' has no readable Xcursor files", name)
+	}
+
+	if err := os.MkdirAll(filepath.Join(outDir, hyprcursorDir), 0o755); err != nil {
+		return 0, err
+	}
+
+	manifest := fmt.Sprintf("name = %s\ndescription = %s converted from Xcursor by lookctl\nversion = 1.0\ncursors_directory = %s\n", name, name, hyprcursorDir)
+
+	if err := writeFileAtomic(filepath.Join(outDir, hyprcursorManifest), []byte(manifest), 0o644); err != nil {
+		return 0, err
+	}
+
+	for _, shape := range shapes {
+		data, err := encodeHyprcursorShape(shape)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode '%s': %w", shape.name, err)
+		}
+
+		if err := writeFileAtomic(filepath.Join(outDir, hyprcursorDir, shape.name+".hlc"), data, 0o644); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(shapes), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestConvertToHyprcursor(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	themePath := filepath.Join(iconDirPath, "Bibata")
+	cursorsPath := filepath.Join(themePath, "cursors")
+
+	test.CreateEmptyDir(t, cursorsPath)
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	pointer := encodeTestXcursor([]xcursorImage{newTestXcursorImage(24, 0), newTestXcursorImage(48, 0)})
+	test.RequireNoError(t, os.WriteFile(filepath.Join(cursorsPath, "left_ptr"), pointer, 0o644))
+
+	watch := encodeTestXcursor([]xcursorImage{newTestXcursorImage(24, 30), newTestXcursorImage(24, 40)})
+	test.RequireNoError(t, os.WriteFile(filepath.Join(cursorsPath, "watch"), watch, 0o644))
+
+	test.RequireNoError(t, os.Symlink("left_ptr", filepath.Join(cursorsPath, "default")))
+	test.RequireNoError(t, os.Symlink("default", filepath.Join(cursorsPath, "arrow")))
+	test.RequireNoError(t, os.Symlink("nowhere", filepath.Join(cursorsPath, "xterm")))
+
+	outDir := filepath.Join(t.TempDir(), "Bibata-hypr")
+
+	count, err := convertToHyprcursor("Bibata", outDir)
+	test.RequireNoError(t, err)
+
+	if count != 2 {
+		t.Errorf("got %d shapes; want 2", count)
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(outDir, hyprcursorManifest))
+	test.RequireNoError(t, err)
+
+	if !strings.Contains(string(manifest), "cursors_directory = hyprcursors\n") {
+		t.Errorf("unexpected manifest: %q", manifest)
+	}
+
+	wantPointer := "resize_algorithm = bilinear\nhotspot_x = 0.020833333333333332\nhotspot_y = 0.041666666666666664\n\n" +
+		"define_override = arrow;default\n\n" +
+		"define_size = 24, left_ptr_24_0.png\ndefine_size = 48, left_ptr_48_0.png\n"
+
+	if got := readHyprcursorMeta(t, filepath.Join(outDir, hyprcursorDir, "left_ptr.hlc")); got != wantPointer {
+		t.Errorf("got left_ptr meta %q; want %q", got, wantPointer)
+	}
+
+	wantWatch := "resize_algorithm = bilinear\nhotspot_x = 0.041666666666666664\nhotspot_y = 0.08333333333333333\n\n" +
+		"define_size = 24, watch_24_0.png, 30\ndefine_size = 24, watch_24_1.png, 40\n"
+
+	if got := readHyprcursorMeta(t, filepath.Join(outDir, hyprcursorDir, "watch.hlc")); got != wantWatch {
+		t.Errorf("got watch meta %q; want %q", got, wantWatch)
+	}
+
+	// the converted theme is recognised once it is installed
+	test.RequireNoError(t, os.Rename(outDir, filepath.Join(iconDirPath, "Bibata-hypr")))
+	test.AssertStringSlicesEqual(t, getInstalledCursorThemes(), []string{"Bibata", "Bibata-hypr"})
+}
+
+func readHyprcursorMeta(t testing.TB, path string) string {
+	t.Helper()
+
+	archive, err := zip.OpenReader(path)
+	test.RequireNoError(t, err)
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != hyprcursorMeta {
+			continue
+		}
+
+		r, err := file.Open()
+		test.RequireNoError(t, err)
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		test.RequireNoError(t, err)
+
+		return string(data)
+	}
+
+	t.Fatalf("%s has no %s", path, hyprcursorMeta)
+
+	return ""
+}
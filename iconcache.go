@@ -0,0 +1,431 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The GTK icon cache format, as written by gtk-update-icon-cache. All
+// values are big endian and offsets are relative to the start of the file.
+const (
+	iconCacheFile         = "icon-theme.cache"
+	iconCacheMajorVersion = 1
+	iconCacheMinorVersion = 0
+	iconCacheHeaderSize   = 12
+	iconCacheNone         = 0xffffffff
+
+	iconFlagXPM      = 1
+	iconFlagSVG      = 2
+	iconFlagPNG      = 4
+	iconFlagIconFile = 8
+)
+
+var iconCacheFlags = map[string]uint16{
+	".xpm":  iconFlagXPM,
+	".svg":  iconFlagSVG,
+	".png":  iconFlagPNG,
+	".icon": iconFlagIconFile,
+}
+
+// iconIndex lists, for every icon name in a theme, the directories that
+// contain it and which file types were found there.
+type iconIndex struct {
+	dirs  []string
+	icons map[string]map[int]uint16
+}
+
+// iconCacheStatus is the result of verifying an existing cache.
+type iconCacheStatus struct {
+	Theme       string   `json:"theme"`
+	Path        string   `json:"path"`
+	Exists      bool     `json:"exists"`
+	Icons       int      `json:"icons"`
+	Directories int      `json:"directories"`
+	Stale       []string `json:"stale"`
+	Problems    []string `json:"problems"`
+}
+
+// iconNameHash is the string hash GTK uses for cache buckets. It works on
+// signed chars, so bytes above 0x7f are sign extended.
+func iconNameHash(name string) uint32 {
+	if name == "" {
+		return 0
+	}
+
+	h := uint32(int8(name[0]))
+	for i := 1; i < len(name); i++ {
+		h = (h << 5) - h + uint32(int8(name[i]))
+	}
+
+	return h
+}
+
+func getIconThemeDir(name string) (string, error) {
+	theme, err := loadIconTheme(name)
+	if err != nil {
+		return "", err
+	}
+
+	return theme.paths[0], nil
+}
+
+// scanIconDirs walks every subdirectory of an icon theme, following
+// symlinked directories once, and indexes the icons found in them.
+func scanIconDirs(root string) (*iconIndex, error) {
+	index := &iconIndex{dirs: []string{}, icons: map[string]map[int]uint16{}}
+	visited := map[string]bool{}
+
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		path := filepath.Join(root, rel)
+
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return err
+		}
+
+		if visited[resolved] {
+			return nil
+		}
+
+		visited[resolved] = true
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+
+		dirIndex := -1
+
+		for _, entry := range entries {
+			entryRel := filepath.Join(rel, entry.Name())
+
+			if isDir(filepath.Join(root, entryRel)) {
+				if err := walk(entryRel); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			// files at the top level, such as index.theme, are not icons
+			if rel == "" {
+				continue
+			}
+
+			ext := filepath.Ext(entry.Name())
+
+			flag, ok := iconCacheFlags[ext]
+			if !ok {
+				continue
+			}
+
+			if dirIndex < 0 {
+				dirIndex = len(index.dirs)
+				index.dirs = append(index.dirs, rel)
+			}
+
+			icon := strings.TrimSuffix(entry.Name(), ext)
+			if index.icons[icon] == nil {
+				index.icons[icon] = map[int]uint16{}
+			}
+
+			index.icons[icon][dirIndex] |= flag
+		}
+
+		return nil
+	}
+
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// iconCacheBuckets returns a prime bucket count a little larger than the
+// number of icons, keeping hash chains short.
+func iconCacheBuckets(n int) int {
+	isPrime := func(v int) bool {
+		for d := 2; d*d <= v; d++ {
+			if v%d == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	candidate := max(n+n/2, 11)
+	for !isPrime(candidate) {
+		candidate++
+	}
+
+	return candidate
+}
+
+func appendCacheString(data []byte, s string) []byte {
+	data = append(data, s...)
+	data = append(data, 0)
+
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+
+	return data
+}
+
+// encodeIconCache serializes an index in the icon-theme.cache format. Image
+// data is never embedded, which matches gtk-update-icon-cache's default.
+func encodeIconCache(index *iconIndex) []byte {
+	be := binary.BigEndian
+
+	names := make([]string, 0, len(index.icons))
+	for name := range index.icons {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	nBuckets := iconCacheBuckets(len(names))
+	buckets := make([][]string, nBuckets)
+
+	for _, name := range names {
+		b := iconNameHash(name) % uint32(nBuckets)
+		buckets[b] = append(buckets[b], name)
+	}
+
+	data := make([]byte, iconCacheHeaderSize)
+	be.PutUint16(data[0:], iconCacheMajorVersion)
+	be.PutUint16(data[2:], iconCacheMinorVersion)
+	be.PutUint32(data[4:], iconCacheHeaderSize)
+
+	hashOffset := len(data)
+	data = be.AppendUint32(data, uint32(nBuckets))
+
+	for range nBuckets {
+		data = be.AppendUint32(data, iconCacheNone)
+	}
+
+	for b, chain := range buckets {
+		previous := hashOffset + 4 + b*4
+
+		for _, name := range chain {
+			icon := len(data)
+			be.PutUint32(data[previous:], uint32(icon))
+
+			// chain, name, and image list offsets, patched below
+			data = be.AppendUint32(data, iconCacheNone)
+			data = be.AppendUint32(data, 0)
+			data = be.AppendUint32(data, 0)
+
+			be.PutUint32(data[icon+4:], uint32(len(data)))
+			data = appendCacheString(data, name)
+
+			images := index.icons[name]
+
+			dirs := make([]int, 0, len(images))
+			for dir := range images {
+				dirs = append(dirs, dir)
+			}
+
+			slices.Sort(dirs)
+
+			be.PutUint32(data[icon+8:], uint32(len(data)))
+			data = be.AppendUint32(data, uint32(len(dirs)))
+
+			for _, dir := range dirs {
+				data = be.AppendUint16(data, uint16(dir))
+				data = be.AppendUint16(data, images[dir])
+				data = be.AppendUint32(data, 0)
+			}
+
+			previous = icon
+		}
+	}
+
+	be.PutUint32(data[8:], uint32(len(data)))
+
+	dirList := len(data)
+	data = be.AppendUint32(data, uint32(len(index.dirs)))
+
+	for range index.dirs {
+		data = be.AppendUint32(data, 0)
+	}
+
+	for i, dir := range index.dirs {
+		be.PutUint32(data[dirList+4+i*4:], uint32(len(data)))
+		data = appendCacheString(data, filepath.ToSlash(dir))
+	}
+
+	return data
+}
+
+// parseIconCache checks the structure of a cache and returns the number of
+// icons and directories it lists.
+func parseIconCache(data []byte) (int, int, error) {
+	be := binary.BigEndian
+
+	u32 := func(offset uint32) (uint32, error) {
+		if uint64(offset)+4 > uint64(len(data)) {
+			return 0, fmt.Errorf("offset %d out of range", offset)
+		}
+
+		return be.Uint32(data[offset:]), nil
+	}
+
+	if len(data) < iconCacheHeaderSize {
+		return 0, 0, errors.New("file too short")
+	}
+
+	if major, minor := be.Uint16(data[0:]), be.Uint16(data[2:]); major != iconCacheMajorVersion || minor != iconCacheMinorVersion {
+		return 0, 0, fmt.Errorf("unsupported cache version %d.%d", major, minor)
+	}
+
+	hashOffset := be.Uint32(data[4:])
+	dirListOffset := be.Uint32(data[8:])
+
+	nDirs, err := u32(dirListOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if _, err := u32(dirListOffset + nDirs*4); nDirs > 0 && err != nil {
+		return 0, 0, err
+	}
+
+	nBuckets, err := u32(hashOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	icons := 0
+
+	for b := range nBuckets {
+		offset, err := u32(hashOffset + 4 + b*4)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for offset != iconCacheNone {
+			icons++
+
+			if icons > len(data)/12 {
+				return 0, 0, errors.New("hash chain loops")
+			}
+
+			imageList, err := u32(offset + 8)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			if _, err := u32(imageList); err != nil {
+				return 0, 0, err
+			}
+
+			offset, err = u32(offset)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	return icons, int(nDirs), nil
+}
+
+func buildIconCache(name string) (string, int, error) {
+	root, err := getIconThemeDir(name)
+	if err != nil {
+		return "", 0, err
+	}
+
+	index, err := scanIconDirs(root)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	path := filepath.Join(root, iconCacheFile)
+
+	if err := writeFileAtomic(path, encodeIconCache(index), 0o644); err != nil {
+		return "", 0, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	// renaming the cache into place touches the theme directory, so bump
+	// the cache past it or it would look stale straight away
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		return "", 0, err
+	}
+
+	return path, len(index.icons), nil
+}
+
+// verifyIconCache validates the cache of a theme and lists the directories
+// modified after it was written, which GTK would otherwise not notice.
+func verifyIconCache(name string) (iconCacheStatus, error) {
+	root, err := getIconThemeDir(name)
+	if err != nil {
+		return iconCacheStatus{}, err
+	}
+
+	status := iconCacheStatus{
+		Theme:    name,
+		Path:     filepath.Join(root, iconCacheFile),
+		Stale:    []string{},
+		Problems: []string{},
+	}
+
+	info, err := os.Stat(status.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		status.Problems = append(status.Problems, "no icon cache")
+		return status, nil
+	}
+
+	if err != nil {
+		return iconCacheStatus{}, err
+	}
+
+	status.Exists = true
+
+	data, err := os.ReadFile(status.Path)
+	if err != nil {
+		return iconCacheStatus{}, err
+	}
+
+	status.Icons, status.Directories, err = parseIconCache(data)
+	if err != nil {
+		status.Problems = append(status.Problems, "invalid cache: "+err.Error())
+		return status, nil
+	}
+
+	index, err := scanIconDirs(root)
+	if err != nil {
+		return iconCacheStatus{}, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	for _, dir := range append([]string{"."}, index.dirs...) {
+		if dirInfo, err := os.Stat(filepath.Join(root, dir)); err == nil && newerThan(dirInfo.ModTime(), info.ModTime()) {
+			status.Stale = append(status.Stale, dir)
+		}
+	}
+
+	if len(status.Stale) > 0 {
+		status.Problems = append(status.Problems, fmt.Sprintf("cache is older than %d director(ies)", len(status.Stale)))
+	}
+
+	if len(index.icons) != status.Icons {
+		status.Problems = append(status.Problems, fmt.Sprintf("cache lists %d icons but the theme has %d", status.Icons, len(index.icons)))
+	}
+
+	return status, nil
+}
+
+func newerThan(a, b time.Time) bool {
+	return a.Truncate(time.Second).After(b.Truncate(time.Second))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestIconNameHash(t *testing.T) {
+	tests := map[string]uint32{
+		"":     0,
+		"a":    97,
+		"ab":   97*31 + 98,
+		"\xe9": 0xffffffe9,
+	}
+
+	for name, want := range tests {
+		if got := iconNameHash(name); got != want {
+			t.Errorf("iconNameHash(%q) = %#x; want %#x", name, got, want)
+		}
+	}
+}
+
+// lookupIconCache finds an icon the same way GTK does and returns the
+// directory names and flags of its images.
+func lookupIconCache(t testing.TB, data []byte, icon string) map[string]uint16 {
+	t.Helper()
+
+	be := binary.BigEndian
+
+	hashOffset := be.Uint32(data[4:])
+	dirListOffset := be.Uint32(data[8:])
+
+	cString := func(offset uint32) string {
+		end := offset
+		for data[end] != 0 {
+			end++
+		}
+
+		return string(data[offset:end])
+	}
+
+	nBuckets := be.Uint32(data[hashOffset:])
+	offset := be.Uint32(data[hashOffset+4+(iconNameHash(icon)%nBuckets)*4:])
+
+	for offset != iconCacheNone {
+		if cString(be.Uint32(data[offset+4:])) == icon {
+			imageList := be.Uint32(data[offset+8:])
+			images := map[string]uint16{}
+
+			for i := range be.Uint32(data[imageList:]) {
+				image := imageList + 4 + i*8
+				dir := be.Uint16(data[image:])
+
+				images[cString(be.Uint32(data[dirListOffset+4+uint32(dir)*4:]))] = be.Uint16(data[image+2:])
+			}
+
+			return images
+		}
+
+		offset = be.Uint32(data[offset:])
+	}
+
+	return nil
+}
+
+func TestBuildIconCache(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	createIconTheme(t, iconDirPath, "Papirus", "[Icon Theme]\nName=Papirus\nDirectories=16x16/apps,scalable/apps\n",
+		"16x16/apps/firefox.png",
+		"16x16/apps/firefox.svg",
+		"16x16/apps/notes.txt",
+		"scalable/apps/firefox.svg",
+		"scalable/apps/terminal.xpm",
+		"scalable/apps/terminal.icon",
+	)
+
+	path, count, err := buildIconCache("Papirus")
+	test.RequireNoError(t, err)
+
+	if count != 2 {
+		t.Errorf("got %d icons; want 2", count)
+	}
+
+	data, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	icons, dirs, err := parseIconCache(data)
+	test.RequireNoError(t, err)
+
+	if icons != 2 || dirs != 2 {
+		t.Errorf("got %d icons in %d directories; want 2 in 2", icons, dirs)
+	}
+
+	firefox := lookupIconCache(t, data, "firefox")
+	if len(firefox) != 2 || firefox["16x16/apps"] != iconFlagPNG|iconFlagSVG || firefox["scalable/apps"] != iconFlagSVG {
+		t.Errorf("got firefox images %v", firefox)
+	}
+
+	terminal := lookupIconCache(t, data, "terminal")
+	if len(terminal) != 1 || terminal["scalable/apps"] != iconFlagXPM|iconFlagIconFile {
+		t.Errorf("got terminal images %v", terminal)
+	}
+
+	if lookupIconCache(t, data, "missing") != nil {
+		t.Error("found an icon that is not in the theme")
+	}
+
+	status, err := verifyIconCache("Papirus")
+	test.RequireNoError(t, err)
+
+	if len(status.Problems) != 0 {
+		t.Errorf("got problems for a fresh cache: %v", status.Problems)
+	}
+
+	future := time.Now().Add(time.Hour)
+	test.RequireNoError(t, os.Chtimes(filepath.Join(iconDirPath, "Papirus", "scalable", "apps"), future, future))
+
+	status, err = verifyIconCache("Papirus")
+	test.RequireNoError(t, err)
+
+	test.AssertStringSlicesEqual(t, status.Stale, []string{"scalable/apps"})
+}
+
+func TestVerifyIconCacheInvalid(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	createIconTheme(t, iconDirPath, "Papirus", "[Icon Theme]\nName=Papirus\n", "16x16/apps/firefox.png")
+
+	status, err := verifyIconCache("Papirus")
+	test.RequireNoError(t, err)
+
+	if status.Exists || len(status.Problems) != 1 {
+		t.Errorf("got %+v for a missing cache", status)
+	}
+
+	err = os.WriteFile(filepath.Join(iconDirPath, "Papirus", iconCacheFile), []byte{0, 1, 0, 0, 0, 0, 0, 12, 0xff, 0, 0, 0}, 0o644)
+	test.RequireNoError(t, err)
+
+	status, err = verifyIconCache("Papirus")
+	test.RequireNoError(t, err)
+
+	if !status.Exists || len(status.Problems) != 1 {
+		t.Errorf("got %+v for a corrupt cache", status)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type iconUsage struct {
+	Icon string   `json:"icon"`
+	Apps []string `json:"apps"`
+}
+
+type iconCoverage struct {
+	Theme    string      `json:"theme"`
+	Total    int         `json:"total"`
+	Score    float64     `json:"score"`
+	Native   []iconUsage `json:"native"`
+	Fallback []iconUsage `json:"fallback"`
+	Missing  []iconUsage `json:"missing"`
+}
+
+// getApplicationIcons collects the Icon key of every visible desktop entry
+// in the data dirs, keyed by icon name. Desktop files in higher precedence
+// data dirs shadow ones with the same desktop file ID in lower ones.
+func getApplicationIcons() []iconUsage {
+	seenIDs := map[string]bool{}
+	icons := map[string][]string{}
+
+	for _, dataDir := range getDataDirs() {
+		appDir := filepath.Join(dataDir, "applications")
+		if !isDir(appDir) {
+			continue
+		}
+
+		filepath.WalkDir(appDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".desktop") {
+				return nil
+			}
+
+			rel, err := filepath.Rel(appDir, path)
+			if err != nil {
+				return nil
+			}
+
+			id := strings.ReplaceAll(rel, string(filepath.Separator), "-")
+			if seenIDs[id] {
+				return nil
+			}
+
+			seenIDs[id] = true
+
+			entry, err := readDesktopEntry(path)
+			if err != nil {
+				return nil
+			}
+
+			group := entry.group("Desktop Entry")
+
+			if hidden, _ := group.get("Hidden"); hidden == "true" {
+				return nil
+			}
+
+			if noDisplay, _ := group.get("NoDisplay"); noDisplay == "true" {
+				return nil
+			}
+
+			icon, _ := group.get("Icon")
+			if icon == "" || filepath.IsAbs(icon) {
+				return nil
+			}
+
+			for _, ext := range iconExtensions {
+				icon = strings.TrimSuffix(icon, ext)
+			}
+
+			icons[icon] = append(icons[icon], id)
+
+			return nil
+		})
+	}
+
+	usages := []iconUsage{}
+
+	for _, icon := range slices.Sorted(maps.Keys(icons)) {
+		apps := icons[icon]
+		slices.Sort(apps)
+
+		usages = append(usages, iconUsage{Icon: icon, Apps: apps})
+	}
+
+	return usages
+}
+
+// getIconThemeChain loads a theme and its inheritance chain in lookup
+// order, skipping hicolor and any parent that is not installed.
+func getIconThemeChain(name string) ([]*iconTheme, error) {
+	root, err := loadIconTheme(name)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []*iconTheme{}
+	visited := map[string]bool{hicolorTheme: true}
+
+	var walk func(theme *iconTheme)
+	walk = func(theme *iconTheme) {
+		if visited[theme.name] {
+			return
+		}
+
+		visited[theme.name] = true
+		chain = append(chain, theme)
+
+		for _, parent := range theme.inherits {
+			if visited[parent] {
+				continue
+			}
+
+			if parentTheme, err := loadIconTheme(parent); err == nil {
+				walk(parentTheme)
+			}
+		}
+	}
+
+	walk(root)
+
+	return chain, nil
+}
+
+// getIconNames returns the name of every icon the theme ships in any of its
+// directories, regardless of size.
+func (t *iconTheme) getIconNames() map[string]bool {
+	names := map[string]bool{}
+
+	for _, base := range t.paths {
+		for _, dir := range t.dirs {
+			entries, err := os.ReadDir(filepath.Join(base, dir.name))
+			if err != nil {
+				continue
+			}
+
+			for _, entry := range entries {
+				ext := filepath.Ext(entry.Name())
+				if slices.Contains(iconExtensions, ext) {
+					names[strings.TrimSuffix(entry.Name(), ext)] = true
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+func getIconCoverage(themeName string) (iconCoverage, error) {
+	chain, err := getIconThemeChain(themeName)
+	if err != nil {
+		return iconCoverage{}, err
+	}
+
+	native := map[string]bool{}
+	for _, theme := range chain {
+		maps.Copy(native, theme.getIconNames())
+	}
+
+	fallback := map[string]bool{}
+	if hicolor, err := loadIconTheme(hicolorTheme); err == nil {
+		fallback = hicolor.getIconNames()
+	}
+
+	coverage := iconCoverage{
+		Theme:    themeName,
+		Native:   []iconUsage{},
+		Fallback: []iconUsage{},
+		Missing:  []iconUsage{},
+	}
+
+	for _, usage := range getApplicationIcons() {
+		switch {
+		case native[usage.Icon]:
+			coverage.Native = append(coverage.Native, usage)
+		case fallback[usage.Icon] || lookupFallbackIcon(usage.Icon) != "":
+			coverage.Fallback = append(coverage.Fallback, usage)
+		default:
+			coverage.Missing = append(coverage.Missing, usage)
+		}
+
+		coverage.Total++
+	}
+
+	if coverage.Total > 0 {
+		coverage.Score = float64(len(coverage.Native)) * 100 / float64(coverage.Total)
+	}
+
+	return coverage, nil
+}
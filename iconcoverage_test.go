@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestGetIconCoverage(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+	appDirPath := filepath.Join(filepath.Dir(iconDirPath), "applications")
+
+	index := func(inherits string) string {
+		return "[Icon Theme]\nInherits=" + inherits + "\nDirectories=apps\n\n[apps]\nSize=48\n"
+	}
+
+	createIconTheme(t, iconDirPath, "Mine", index("Base"), "apps/firefox.svg")
+	createIconTheme(t, iconDirPath, "Base", index(""), "apps/terminal.png")
+	createIconTheme(t, iconDirPath, hicolorTheme, index(""), "apps/vendor.png")
+
+	apps := map[string]string{
+		"firefox.desktop":        "[Desktop Entry]\nName=Firefox\nIcon=firefox\n",
+		"terminal.desktop":       "[Desktop Entry]\nName=Terminal\nIcon=terminal.png\n",
+		"vendor/app.desktop":     "[Desktop Entry]\nName=Vendor\nIcon=vendor\n",
+		"broken.desktop":         "[Desktop Entry]\nName=Broken\nIcon=nothing\n",
+		"hidden.desktop":         "[Desktop Entry]\nName=Hidden\nIcon=hidden\nNoDisplay=true\n",
+		"absolute.desktop":       "[Desktop Entry]\nName=Absolute\nIcon=/opt/app/icon.png\n",
+		"firefox-second.desktop": "[Desktop Entry]\nName=Firefox Private\nIcon=firefox\n",
+	}
+
+	for name, content := range apps {
+		path := filepath.Join(appDirPath, name)
+		test.CreateEmptyDir(t, filepath.Dir(path))
+
+		err := os.WriteFile(path, []byte(content), 0o644)
+		test.RequireNoError(t, err)
+	}
+
+	got, err := getIconCoverage("Mine")
+	test.RequireNoError(t, err)
+
+	icons := func(usages []iconUsage) []string {
+		names := []string{}
+		for _, usage := range usages {
+			names = append(names, usage.Icon)
+		}
+
+		return names
+	}
+
+	test.AssertStringSlicesEqual(t, icons(got.Native), []string{"firefox", "terminal"})
+	test.AssertStringSlicesEqual(t, icons(got.Fallback), []string{"vendor"})
+	test.AssertStringSlicesEqual(t, icons(got.Missing), []string{"nothing"})
+	test.AssertStringSlicesEqual(t, got.Native[0].Apps, []string{"firefox-second.desktop", "firefox.desktop"})
+	test.AssertStringSlicesEqual(t, got.Fallback[0].Apps, []string{"vendor-app.desktop"})
+
+	if got.Total != 4 || got.Score != 50 {
+		t.Errorf("got total %d and score %.1f; want 4 and 50.0", got.Total, got.Score)
+	}
+}
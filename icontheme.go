@@ -0,0 +1,357 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const hicolorTheme = "hicolor"
+
+var iconExtensions = []string{".png", ".svg", ".xpm"}
+
+const (
+	iconDirFixed     = "Fixed"
+	iconDirScalable  = "Scalable"
+	iconDirThreshold = "Threshold"
+)
+
+type iconDir struct {
+	name      string
+	size      int
+	scale     int
+	minSize   int
+	maxSize   int
+	threshold int
+	kind      string
+}
+
+// iconTheme is an icon theme as described by the Icon Theme Specification.
+// A theme may be spread over several base directories, all of which are
+// searched, but its index.theme is read from the first one that has it.
+type iconTheme struct {
+	name     string
+	paths    []string
+	inherits []string
+	dirs     []iconDir
+}
+
+func loadIconTheme(name string) (*iconTheme, error) {
+	theme := &iconTheme{name: name}
+
+	var index *desktopEntry
+
+	for _, base := range getAssetSearchPaths("icons", ".icons") {
+		path := filepath.Join(base, name)
+		if !isDir(path) {
+			continue
+		}
+
+		theme.paths = append(theme.paths, path)
+
+		if index == nil && isFile(filepath.Join(path, "index.theme")) {
+			parsed, err := readDesktopEntry(filepath.Join(path, "index.theme"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read index.theme of %s: %w", path, err)
+			}
+
+			index = parsed
+		}
+	}
+
+	if index == nil {
+		return nil, fmt.Errorf("icon theme '%s' not found", name)
+	}
+
+	group := index.group("Icon Theme")
+
+	theme.inherits = group.getList("Inherits", ',')
+
+	dirNames := append(group.getList("Directories", ','), group.getList("ScaledDirectories", ',')...)
+
+	for _, dirName := range dirNames {
+		dir, ok := parseIconDir(index.group(dirName), dirName)
+		if ok {
+			theme.dirs = append(theme.dirs, dir)
+		}
+	}
+
+	return theme, nil
+}
+
+func parseIconDir(g *desktopGroup, name string) (iconDir, bool) {
+	intValue := func(key string, fallback int) int {
+		value, ok := g.get(key)
+		if !ok {
+			return fallback
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fallback
+		}
+
+		return n
+	}
+
+	size := intValue("Size", 0)
+	if size <= 0 {
+		return iconDir{}, false
+	}
+
+	kind, ok := g.get("Type")
+	if !ok {
+		kind = iconDirThreshold
+	}
+
+	return iconDir{
+		name:      name,
+		size:      size,
+		scale:     intValue("Scale", 1),
+		minSize:   intValue("MinSize", size),
+		maxSize:   intValue("MaxSize", size),
+		threshold: intValue("Threshold", 2),
+		kind:      kind,
+	}, true
+}
+
+func (d iconDir) matchesSize(size, scale int) bool {
+	if d.scale != scale {
+		return false
+	}
+
+	switch d.kind {
+	case iconDirFixed:
+		return d.size == size
+	case iconDirScalable:
+		return d.minSize <= size && size <= d.maxSize
+	default:
+		return d.size-d.threshold <= size && size <= d.size+d.threshold
+	}
+}
+
+func (d iconDir) sizeDistance(size, scale int) int {
+	scaled := size * scale
+
+	switch d.kind {
+	case iconDirFixed:
+		return abs(d.size*d.scale - scaled)
+	case iconDirScalable:
+		if scaled < d.minSize*d.scale {
+			return d.minSize*d.scale - scaled
+		}
+
+		if scaled > d.maxSize*d.scale {
+			return scaled - d.maxSize*d.scale
+		}
+
+		return 0
+	default:
+		// the threshold is not scaled, as in the spec's DirectorySizeDistance
+		if scaled < d.size*d.scale-d.threshold {
+			return d.size*d.scale - d.threshold - scaled
+		}
+
+		if scaled > d.size*d.scale+d.threshold {
+			return scaled - (d.size*d.scale + d.threshold)
+		}
+
+		return 0
+	}
+}
+
+// lookupIcon finds an icon in this theme only, preferring a directory that
+// matches the requested size and falling back to the closest one.
+func (t *iconTheme) lookupIcon(icon string, size, scale int) string {
+	for _, dir := range t.dirs {
+		if !dir.matchesSize(size, scale) {
+			continue
+		}
+
+		if path := t.findInDir(dir, icon); path != "" {
+			return path
+		}
+	}
+
+	closest := ""
+	minDistance := math.MaxInt
+
+	for _, dir := range t.dirs {
+		distance := dir.sizeDistance(size, scale)
+		if distance >= minDistance {
+			continue
+		}
+
+		if path := t.findInDir(dir, icon); path != "" {
+			closest = path
+			minDistance = distance
+		}
+	}
+
+	return closest
+}
+
+func (t *iconTheme) findInDir(dir iconDir, icon string) string {
+	for _, base := range t.paths {
+		for _, ext := range iconExtensions {
+			path := filepath.Join(base, dir.name, icon+ext)
+			if isFile(path) {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+type iconLookupResult struct {
+	Icon  string `json:"icon"`
+	Path  string `json:"path"`
+	Theme string `json:"theme"`
+	Size  int    `json:"size"`
+	Scale int    `json:"scale"`
+}
+
+// findIcon resolves an icon the way the Icon Theme Specification describes:
+// the theme, then its Inherits chain depth first, then hicolor, and finally
+// the unthemed base directories.
+func findIcon(themeName, icon string, size, scale int) (iconLookupResult, bool) {
+	result := iconLookupResult{Icon: icon, Size: size, Scale: scale}
+
+	cache := map[string]*iconTheme{}
+	visited := map[string]bool{}
+
+	if path, theme := findIconHelper(themeName, icon, size, scale, cache, visited); path != "" {
+		result.Path, result.Theme = path, theme
+		return result, true
+	}
+
+	if path, theme := findIconHelper(hicolorTheme, icon, size, scale, cache, visited); path != "" {
+		result.Path, result.Theme = path, theme
+		return result, true
+	}
+
+	if path := lookupFallbackIcon(icon); path != "" {
+		result.Path = path
+		return result, true
+	}
+
+	return result, false
+}
+
+func findIconHelper(themeName, icon string, size, scale int, cache map[string]*iconTheme, visited map[string]bool) (string, string) {
+	if visited[themeName] {
+		return "", ""
+	}
+
+	visited[themeName] = true
+
+	theme, ok := cache[themeName]
+	if !ok {
+		theme, _ = loadIconTheme(themeName)
+		cache[themeName] = theme
+	}
+
+	if theme == nil {
+		return "", ""
+	}
+
+	if path := theme.lookupIcon(icon, size, scale); path != "" {
+		return path, themeName
+	}
+
+	for _, parent := range theme.inherits {
+		if path, found := findIconHelper(parent, icon, size, scale, cache, visited); path != "" {
+			return path, found
+		}
+	}
+
+	return "", ""
+}
+
+func lookupFallbackIcon(icon string) string {
+	baseDirs := getAssetSearchPaths("icons", ".icons")
+
+	for _, dir := range getDataDirs() {
+		if isDir(filepath.Join(dir, "pixmaps")) {
+			baseDirs = append(baseDirs, filepath.Join(dir, "pixmaps"))
+		}
+	}
+
+	for _, dir := range baseDirs {
+		for _, ext := range iconExtensions {
+			path := filepath.Join(dir, icon+ext)
+			if isFile(path) {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// checkIconThemeInheritance walks the whole inheritance graph of an icon
+// theme. It returns every theme reachable from name in lookup order and a
+// description of each missing parent and cycle found along the way.
+func checkIconThemeInheritance(name string) ([]string, []string, error) {
+	root, err := loadIconTheme(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+
+	chain := []string{}
+	problems := []string{}
+	state := map[string]int{}
+	stack := []string{}
+
+	var walk func(theme *iconTheme)
+	walk = func(theme *iconTheme) {
+		state[theme.name] = visiting
+		stack = append(stack, theme.name)
+		chain = append(chain, theme.name)
+
+		for _, parent := range theme.inherits {
+			switch state[parent] {
+			case visiting:
+				start := slices.Index(stack, parent)
+				cycle := append(slices.Clone(stack[start:]), parent)
+				problems = append(problems, fmt.Sprintf("inheritance cycle: %s", strings.Join(cycle, " -> ")))
+				continue
+			case done:
+				continue
+			}
+
+			parentTheme, err := loadIconTheme(parent)
+			if err != nil {
+				state[parent] = done
+				problems = append(problems, fmt.Sprintf("'%s' inherits '%s', which is not installed", theme.name, parent))
+				continue
+			}
+
+			walk(parentTheme)
+		}
+
+		stack = stack[:len(stack)-1]
+		state[theme.name] = done
+	}
+
+	walk(root)
+
+	return chain, problems, nil
+}
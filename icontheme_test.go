@@ -0,0 +1,265 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func createIconTheme(t testing.TB, iconDirPath, name, index string, icons ...string) {
+	t.Helper()
+
+	themePath := filepath.Join(iconDirPath, name)
+	test.CreateEmptyDir(t, themePath)
+
+	err := os.WriteFile(filepath.Join(themePath, "index.theme"), []byte(index), 0o644)
+	test.RequireNoError(t, err)
+
+	for _, icon := range icons {
+		test.CreateEmptyDir(t, filepath.Dir(filepath.Join(themePath, icon)))
+		test.CreateEmptyFile(t, filepath.Join(themePath, icon))
+	}
+}
+
+func TestIconDirSizes(t *testing.T) {
+	tests := []struct {
+		description  string
+		dir          iconDir
+		size         int
+		scale        int
+		wantMatch    bool
+		wantDistance int
+	}{
+		{
+			description:  "fixed exact",
+			dir:          iconDir{kind: iconDirFixed, size: 48, scale: 1},
+			size:         48,
+			scale:        1,
+			wantMatch:    true,
+			wantDistance: 0,
+		},
+		{
+			description:  "fixed other size",
+			dir:          iconDir{kind: iconDirFixed, size: 32, scale: 1},
+			size:         48,
+			scale:        1,
+			wantMatch:    false,
+			wantDistance: 16,
+		},
+		{
+			description:  "scale mismatch",
+			dir:          iconDir{kind: iconDirFixed, size: 24, scale: 2},
+			size:         24,
+			scale:        1,
+			wantMatch:    false,
+			wantDistance: 24,
+		},
+		{
+			description:  "scalable in range",
+			dir:          iconDir{kind: iconDirScalable, size: 48, scale: 1, minSize: 16, maxSize: 256},
+			size:         100,
+			scale:        1,
+			wantMatch:    true,
+			wantDistance: 0,
+		},
+		{
+			description:  "threshold outside",
+			dir:          iconDir{kind: iconDirThreshold, size: 32, scale: 1, threshold: 2},
+			size:         40,
+			scale:        1,
+			wantMatch:    false,
+			wantDistance: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := tt.dir.matchesSize(tt.size, tt.scale); got != tt.wantMatch {
+				t.Errorf("matchesSize got %t; want %t", got, tt.wantMatch)
+			}
+
+			if got := tt.dir.sizeDistance(tt.size, tt.scale); got != tt.wantDistance {
+				t.Errorf("sizeDistance got %d; want %d", got, tt.wantDistance)
+			}
+		})
+	}
+}
+
+func TestFindIcon(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	createIconTheme(t, iconDirPath, "Child", `[Icon Theme]
+Name=Child
+Inherits=Parent
+Directories=16x16/apps,48x48/apps,50x50/apps,32x32@2/apps
+
+[16x16/apps]
+Size=16
+Type=Fixed
+
+[48x48/apps]
+Size=48
+Type=Fixed
+
+[50x50/apps]
+Size=50
+Type=Fixed
+
+[32x32@2/apps]
+Size=32
+Scale=2
+Threshold=4
+Type=Threshold
+`, "16x16/apps/small-only.png", "48x48/apps/firefox.png", "16x16/apps/firefox.png", "50x50/apps/scaled.png", "32x32@2/apps/scaled.png")
+
+	createIconTheme(t, iconDirPath, "Parent", `[Icon Theme]
+Name=Parent
+Directories=scalable/apps
+
+[scalable/apps]
+Size=48
+MinSize=8
+MaxSize=512
+Type=Scalable
+`, "scalable/apps/terminal.svg")
+
+	createIconTheme(t, iconDirPath, hicolorTheme, `[Icon Theme]
+Name=Hicolor
+Directories=48x48/apps
+
+[48x48/apps]
+Size=48
+Type=Threshold
+`, "48x48/apps/vendor-app.png")
+
+	pixmapsPath := filepath.Join(filepath.Dir(iconDirPath), "pixmaps")
+	test.CreateEmptyDir(t, pixmapsPath)
+	test.CreateEmptyFile(t, filepath.Join(pixmapsPath, "legacy.xpm"))
+
+	tests := []struct {
+		description string
+		icon        string
+		size        int
+		wantPath    string
+		wantTheme   string
+	}{
+		{
+			description: "exact size in theme",
+			icon:        "firefox",
+			size:        16,
+			wantPath:    filepath.Join(iconDirPath, "Child", "16x16/apps/firefox.png"),
+			wantTheme:   "Child",
+		},
+		{
+			description: "closest size in theme",
+			icon:        "small-only",
+			size:        48,
+			wantPath:    filepath.Join(iconDirPath, "Child", "16x16/apps/small-only.png"),
+			wantTheme:   "Child",
+		},
+		{
+			// 32x32@2 covers 60 to 68 pixels, 6 away from 54, while the
+			// fixed 50x50 directory is only 4 away
+			description: "threshold of scaled directory is not scaled",
+			icon:        "scaled",
+			size:        54,
+			wantPath:    filepath.Join(iconDirPath, "Child", "50x50/apps/scaled.png"),
+			wantTheme:   "Child",
+		},
+		{
+			description: "inherited from parent",
+			icon:        "terminal",
+			size:        64,
+			wantPath:    filepath.Join(iconDirPath, "Parent", "scalable/apps/terminal.svg"),
+			wantTheme:   "Parent",
+		},
+		{
+			description: "falls back to hicolor",
+			icon:        "vendor-app",
+			size:        48,
+			wantPath:    filepath.Join(iconDirPath, hicolorTheme, "48x48/apps/vendor-app.png"),
+			wantTheme:   hicolorTheme,
+		},
+		{
+			description: "falls back to pixmaps",
+			icon:        "legacy",
+			size:        48,
+			wantPath:    filepath.Join(pixmapsPath, "legacy.xpm"),
+			wantTheme:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got, found := findIcon("Child", tt.icon, tt.size, 1)
+			if !found {
+				t.Fatalf("icon %q not found", tt.icon)
+			}
+
+			if got.Path != tt.wantPath || got.Theme != tt.wantTheme {
+				t.Errorf("got %q from %q; want %q from %q", got.Path, got.Theme, tt.wantPath, tt.wantTheme)
+			}
+		})
+	}
+
+	if _, found := findIcon("Child", "missing", 48, 1); found {
+		t.Error("expected missing icon not to be found")
+	}
+}
+
+func TestCheckIconThemeInheritance(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	index := func(inherits string) string {
+		return "[Icon Theme]\nInherits=" + inherits + "\nDirectories=apps\n\n[apps]\nSize=48\n"
+	}
+
+	createIconTheme(t, iconDirPath, "Good", index("Base,hicolor"))
+	createIconTheme(t, iconDirPath, "Base", index("hicolor"))
+	createIconTheme(t, iconDirPath, hicolorTheme, index(""))
+	createIconTheme(t, iconDirPath, "Orphan", index("Gone,hicolor"))
+	createIconTheme(t, iconDirPath, "LoopA", index("LoopB"))
+	createIconTheme(t, iconDirPath, "LoopB", index("LoopA"))
+
+	tests := []struct {
+		description  string
+		theme        string
+		wantChain    []string
+		wantProblems []string
+	}{
+		{
+			description:  "valid chain",
+			theme:        "Good",
+			wantChain:    []string{"Good", "Base", hicolorTheme},
+			wantProblems: []string{},
+		},
+		{
+			description:  "missing parent",
+			theme:        "Orphan",
+			wantChain:    []string{"Orphan", hicolorTheme},
+			wantProblems: []string{"'Orphan' inherits 'Gone', which is not installed"},
+		},
+		{
+			description:  "cycle",
+			theme:        "LoopA",
+			wantChain:    []string{"LoopA", "LoopB"},
+			wantProblems: []string{"inheritance cycle: LoopA -> LoopB -> LoopA"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			chain, problems, err := checkIconThemeInheritance(tt.theme)
+			test.RequireNoError(t, err)
+
+			if !slices.Equal(chain, tt.wantChain) {
+				t.Errorf("got chain %v; want %v", chain, tt.wantChain)
+			}
+
+			test.AssertStringSlicesEqual(t, problems, tt.wantProblems)
+		})
+	}
+}
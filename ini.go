@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strings"
+)
+
+type iniLineKind int
+
+const (
+	iniBlank iniLineKind = iota
+	iniComment
+	iniSection
+	iniKey
+	iniOther
+)
+
+// iniLine keeps the raw text of a line so untouched lines are written back
+// exactly as they were read.
+type iniLine struct {
+	raw     string
+	kind    iniLineKind
+	section string
+	key     string
+	prefix  string
+	value   string
+}
+
+// iniFile is a minimal, order preserving INI document. Only the lines
+// modified through set are re-rendered.
+type iniFile struct {
+	lines []iniLine
+}
+
+func parseINI(data []byte) *iniFile {
+	f := &iniFile{}
+	section := ""
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		line := parseINILine(raw, section)
+		if line.kind == iniSection {
+			section = line.section
+		}
+
+		f.lines = append(f.lines, line)
+	}
+
+	return f
+}
+
+func parseINILine(raw, section string) iniLine {
+	line := iniLine{raw: raw, section: section}
+
+	trimmed := strings.TrimSpace(raw)
+
+	switch {
+	case trimmed == "":
+		line.kind = iniBlank
+	case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, ";"):
+		line.kind = iniComment
+	case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+		line.kind = iniSection
+		line.section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	case strings.Contains(trimmed, "="):
+		idx := strings.Index(raw, "=")
+		rest := raw[idx+1:]
+		value := strings.TrimLeft(rest, " \t")
+
+		line.kind = iniKey
+		line.key = strings.TrimSpace(raw[:idx])
+		line.prefix = raw[:len(raw)-len(value)]
+		line.value = strings.TrimSpace(value)
+	default:
+		line.kind = iniOther
+	}
+
+	return line
+}
+
+func (f *iniFile) get(section, key string) (string, bool) {
+	value, found := "", false
+
+	for _, line := range f.lines {
+		if line.kind == iniKey && line.section == section && line.key == key {
+			value, found = line.value, true
+		}
+	}
+
+	return value, found
+}
+
+// set updates every occurrence of key in section, or inserts it after the
+// last line of the section, appending the section if it does not exist.
+func (f *iniFile) set(section, key, value string) {
+	found := false
+
+	for i, line := range f.lines {
+		if line.kind != iniKey || line.section != section || line.key != key {
+			continue
+		}
+
+		if line.value == value {
+			found = true
+			continue
+		}
+
+		eol := ""
+		if strings.HasSuffix(line.raw, "\r") {
+			eol = "\r"
+		}
+
+		f.lines[i].raw = line.prefix + value + eol
+		f.lines[i].value = value
+		found = true
+	}
+
+	if found {
+		return
+	}
+
+	newLine := iniLine{
+		raw:     key + "=" + value + f.eol(),
+		kind:    iniKey,
+		section: section,
+		key:     key,
+		prefix:  key + "=",
+		value:   value,
+	}
+
+	insertAt := -1
+	for i, line := range f.lines {
+		if line.section == section && line.kind != iniBlank {
+			insertAt = i + 1
+		}
+	}
+
+	if insertAt < 0 && section == "" {
+		insertAt = 0
+	}
+
+	if insertAt >= 0 {
+		f.lines = append(f.lines[:insertAt], append([]iniLine{newLine}, f.lines[insertAt:]...)...)
+		return
+	}
+
+	trailing := len(f.lines) > 0 && f.lines[len(f.lines)-1].raw == ""
+	if trailing {
+		f.lines = f.lines[:len(f.lines)-1]
+	}
+
+	if len(f.lines) > 0 && f.lines[len(f.lines)-1].kind != iniBlank {
+		f.lines = append(f.lines, iniLine{raw: f.eol(), kind: iniBlank, section: f.lines[len(f.lines)-1].section})
+	}
+
+	if section != "" {
+		f.lines = append(f.lines, iniLine{raw: "[" + section + "]" + f.eol(), kind: iniSection, section: section})
+	}
+
+	f.lines = append(f.lines, newLine, iniLine{kind: iniBlank, section: section})
+}
+
+func (f *iniFile) eol() string {
+	for _, line := range f.lines {
+		if strings.HasSuffix(line.raw, "\r") {
+			return "\r"
+		}
+	}
+
+	return ""
+}
+
+func (f *iniFile) bytes() []byte {
+	raws := make([]string, len(f.lines))
+	for i, line := range f.lines {
+		raws[i] = line.raw
+	}
+
+	return []byte(strings.Join(raws, "\n"))
+}
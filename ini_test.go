@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestINIRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"[Settings]\ngtk-theme-name=Adwaita\n",
+		"[Settings]\r\ngtk-theme-name = Adwaita\r\n",
+		"; comment\n# other comment\n\n[Settings]\ngtk-font-name=Cantarell 11\n[Other]\nkey=value",
+		"no section=1\nstray line\n",
+	}
+
+	for _, input := range inputs {
+		got := string(parseINI([]byte(input)).bytes())
+
+		if got != input {
+			t.Errorf("round trip changed content: got %q; want %q", got, input)
+		}
+	}
+}
+
+func TestINISet(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		section     string
+		key         string
+		value       string
+		want        string
+	}{
+		{
+			description: "creates section in empty file",
+			input:       "",
+			section:     "Settings",
+			key:         "gtk-theme-name",
+			value:       "Adwaita",
+			want:        "[Settings]\ngtk-theme-name=Adwaita\n",
+		},
+		{
+			description: "updates existing key preserving spacing",
+			input:       "[Settings]\ngtk-theme-name = Old\ngtk-font-name=Cantarell 11\n",
+			section:     "Settings",
+			key:         "gtk-theme-name",
+			value:       "New",
+			want:        "[Settings]\ngtk-theme-name = New\ngtk-font-name=Cantarell 11\n",
+		},
+		{
+			description: "inserts key at the end of its section",
+			input:       "[Settings]\ngtk-font-name=Cantarell 11\n\n[Other]\nkey=value\n",
+			section:     "Settings",
+			key:         "gtk-theme-name",
+			value:       "Adwaita",
+			want:        "[Settings]\ngtk-font-name=Cantarell 11\ngtk-theme-name=Adwaita\n\n[Other]\nkey=value\n",
+		},
+		{
+			description: "appends missing section after unknown sections",
+			input:       "# keep me\n[Other]\nkey=value",
+			section:     "Settings",
+			key:         "gtk-theme-name",
+			value:       "Adwaita",
+			want:        "# keep me\n[Other]\nkey=value\n\n[Settings]\ngtk-theme-name=Adwaita\n",
+		},
+		{
+			description: "keeps CRLF line endings",
+			input:       "[Settings]\r\ngtk-theme-name=Old\r\n",
+			section:     "Settings",
+			key:         "gtk-theme-name",
+			value:       "New",
+			want:        "[Settings]\r\ngtk-theme-name=New\r\n",
+		},
+		{
+			description: "does not match keys from other sections",
+			input:       "[Other]\ngtk-theme-name=Old\n",
+			section:     "Settings",
+			key:         "gtk-theme-name",
+			value:       "New",
+			want:        "[Other]\ngtk-theme-name=Old\n\n[Settings]\ngtk-theme-name=New\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			ini := parseINI([]byte(tt.input))
+			ini.set(tt.section, tt.key, tt.value)
+
+			got := string(ini.bytes())
+
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+
+			value, ok := parseINI([]byte(got)).get(tt.section, tt.key)
+			if !ok || value != tt.value {
+				t.Errorf("get after set returned %q, %t; want %q", value, ok, tt.value)
+			}
+		})
+	}
+}
+
+func TestRenderSettingsIniUnchanged(t *testing.T) {
+	input := "[Settings]\ngtk-theme-name=Adwaita\ngtk-icon-theme-name=Papirus\n# cursor\ngtk-cursor-theme-name=Bibata\ngtk-application-prefer-dark-theme=true\ngtk-font-name=Cantarell 11\n"
+
+	cfg := themeConfig{
+		gtkTheme:    "Adwaita",
+		iconTheme:   "Papirus",
+		cursorTheme: "Bibata",
+		preferDark:  true,
+	}
+
+	got := string(renderSettingsIni([]byte(input), cfg))
+
+	if got != input {
+		t.Errorf("got %q; want %q", got, input)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const envXdgConfigDirs = "XDG_CONFIG_DIRS"
+
+// kconfigFile is a KDE config file. It is an iniFile whose group and key
+// names have the KConfig markers, such as [$i] and [$e], stripped so they
+// can be looked up by name; the raw lines, and so the markers, are written
+// back untouched.
+type kconfigFile struct {
+	ini *iniFile
+	// immutable holds "" when the whole file is locked, group names for
+	// locked groups, and group + "\x00" + key for locked keys.
+	immutable map[string]bool
+	expand    map[string]bool
+	deleted   map[string]bool
+}
+
+func kconfigKey(group, key string) string {
+	return group + "\x00" + key
+}
+
+// splitKConfigMarkers separates trailing [$x] markers from a group or key
+// name. Localized key suffixes such as [de] are part of the name.
+func splitKConfigMarkers(name string) (string, string) {
+	markers := ""
+
+	for strings.HasSuffix(name, "]") {
+		start := strings.LastIndex(name, "[$")
+		if start < 0 {
+			break
+		}
+
+		markers += name[start+2 : len(name)-1]
+		name = name[:start]
+	}
+
+	return name, markers
+}
+
+func parseKConfig(data []byte) *kconfigFile {
+	k := &kconfigFile{
+		ini:       parseINI(data),
+		immutable: map[string]bool{},
+		expand:    map[string]bool{},
+		deleted:   map[string]bool{},
+	}
+
+	group := ""
+
+	for i, line := range k.ini.lines {
+		switch line.kind {
+		case iniSection:
+			// a [$i] line before any group locks the whole file
+			if line.section == "$i" && group == "" {
+				k.immutable[""] = true
+				k.ini.lines[i].section = ""
+
+				continue
+			}
+
+			// nested groups are written as [Parent][Child]
+			parts := strings.Split(line.section, "][")
+
+			var names []string
+
+			for _, part := range parts {
+				if strings.HasPrefix(part, "$") {
+					if strings.Contains(part, "i") {
+						k.immutable[strings.Join(names, "][")] = true
+					}
+
+					continue
+				}
+
+				names = append(names, part)
+			}
+
+			group = strings.Join(names, "][")
+			k.ini.lines[i].section = group
+		case iniKey:
+			key, markers := splitKConfigMarkers(line.key)
+
+			k.ini.lines[i].key = key
+
+			if strings.Contains(markers, "i") {
+				k.immutable[kconfigKey(group, key)] = true
+			}
+
+			if strings.Contains(markers, "e") {
+				k.expand[kconfigKey(group, key)] = true
+			}
+
+			if strings.Contains(markers, "d") {
+				k.deleted[kconfigKey(group, key)] = true
+			}
+
+			k.ini.lines[i].section = group
+		default:
+			k.ini.lines[i].section = group
+		}
+	}
+
+	return k
+}
+
+func (k *kconfigFile) get(group, key string) (string, bool) {
+	if k.deleted[kconfigKey(group, key)] {
+		return "", false
+	}
+
+	value, ok := k.ini.get(group, key)
+	if !ok {
+		return "", false
+	}
+
+	value = unescapeDesktopValue(value)
+
+	if k.expand[kconfigKey(group, key)] {
+		value = os.ExpandEnv(value)
+	}
+
+	return value, true
+}
+
+func (k *kconfigFile) locked(group, key string) bool {
+	return k.immutable[""] || k.immutable[group] || k.immutable[kconfigKey(group, key)]
+}
+
+func (k *kconfigFile) set(group, key, value string) {
+	k.ini.set(group, key, escapeKConfigValue(value))
+}
+
+// escapeKConfigValue escapes a value the way KConfig writes it, so get
+// returns it unchanged: backslashes and control characters are escaped,
+// and so are leading and trailing spaces, which would otherwise be trimmed.
+func escapeKConfigValue(value string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == ' ' && (i == 0 || i == len(value)-1):
+			sb.WriteString(`\s`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+func (k *kconfigFile) bytes() []byte {
+	return k.ini.bytes()
+}
+
+func getSystemConfigDirs() []string {
+	configDirs := os.Getenv(envXdgConfigDirs)
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+
+	dirs := []string{}
+
+	for _, dir := range strings.Split(configDirs, ":") {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// kconfigCascade is a config file as KConfig sees it: the system files
+// from XDG_CONFIG_DIRS, lowest precedence first, followed by the user's.
+type kconfigCascade struct {
+	name  string
+	paths []string
+	files []*kconfigFile
+}
+
+func getKConfigPath(name string) string {
+	return filepath.Join(getConfigDir(), name)
+}
+
+func readKConfigCascade(name string) (*kconfigCascade, error) {
+	c := &kconfigCascade{name: name}
+
+	systemDirs := getSystemConfigDirs()
+	slices.Reverse(systemDirs)
+
+	for _, dir := range append(systemDirs, getConfigDir()) {
+		path := filepath.Join(dir, name)
+
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		c.paths = append(c.paths, path)
+		c.files = append(c.files, parseKConfig(data))
+	}
+
+	return c, nil
+}
+
+// get returns the effective value of a key. A value marked immutable
+// cannot be overridden by files with a higher precedence.
+func (c *kconfigCascade) get(group, key string) (string, bool) {
+	value, found := "", false
+
+	for _, f := range c.files {
+		if v, ok := f.get(group, key); ok {
+			value, found = v, true
+		}
+
+		if f.locked(group, key) {
+			break
+		}
+	}
+
+	return value, found
+}
+
+// lockedBy returns the file that makes a key immutable for the user, or ""
+// when the user's file may change it.
+func (c *kconfigCascade) lockedBy(group, key string) string {
+	for i, f := range c.files {
+		if f.locked(group, key) {
+			return c.paths[i]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestParseKConfig(t *testing.T) {
+	t.Setenv("LOOKCTL_TEST_THEME", "Papirus")
+
+	input := "[General]\nName[de]=Allgemein\nColorScheme=BreezeDark\n\n[Icons]\nTheme[$e]=$LOOKCTL_TEST_THEME-Dark\n\n[KDE][$i]\nwidgetStyle=Breeze\n\n[Mouse]\ncursorTheme[$i]=Bibata\ncursorSize[$d]=\n"
+
+	k := parseKConfig([]byte(input))
+
+	tests := []struct {
+		group string
+		key   string
+		want  string
+		found bool
+	}{
+		{"General", "ColorScheme", "BreezeDark", true},
+		{"General", "Name[de]", "Allgemein", true},
+		{"Icons", "Theme", "Papirus-Dark", true},
+		{"KDE", "widgetStyle", "Breeze", true},
+		{"Mouse", "cursorTheme", "Bibata", true},
+		{"Mouse", "cursorSize", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := k.get(tt.group, tt.key)
+		if got != tt.want || ok != tt.found {
+			t.Errorf("get(%q, %q) = %q, %t; want %q, %t", tt.group, tt.key, got, ok, tt.want, tt.found)
+		}
+	}
+
+	if !k.locked("KDE", "widgetStyle") || !k.locked("Mouse", "cursorTheme") || k.locked("Icons", "Theme") {
+		t.Errorf("got immutable markers %v", k.immutable)
+	}
+
+	k.set("Icons", "Theme", "Adwaita")
+	k.set("General", "ColorScheme", "BreezeLight")
+
+	want := "[General]\nName[de]=Allgemein\nColorScheme=BreezeLight\n\n[Icons]\nTheme[$e]=Adwaita\n\n[KDE][$i]\nwidgetStyle=Breeze\n\n[Mouse]\ncursorTheme[$i]=Bibata\ncursorSize[$d]=\n"
+
+	if got := string(k.bytes()); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestParseKConfigImmutableFile(t *testing.T) {
+	k := parseKConfig([]byte("[$i]\n[Icons]\nTheme=breeze\n"))
+
+	if !k.locked("Icons", "Theme") {
+		t.Error("expected a file level [$i] to lock every key")
+	}
+
+	if value, _ := k.get("Icons", "Theme"); value != "breeze" {
+		t.Errorf("got %q; want %q", value, "breeze")
+	}
+}
+
+func TestKConfigCascade(t *testing.T) {
+	tempDir := t.TempDir()
+	systemDir := filepath.Join(tempDir, "etc", "xdg")
+	userDir := filepath.Join(tempDir, "config")
+
+	t.Setenv(envXdgConfigDirs, systemDir)
+	t.Setenv(envConfigHome, userDir)
+
+	test.CreateEmptyDir(t, systemDir)
+	test.CreateEmptyDir(t, userDir)
+
+	err := os.WriteFile(filepath.Join(systemDir, "kdeglobals"), []byte("[Icons][$i]\nTheme=corporate\n\n[General]\nColorScheme=BreezeLight\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	err = os.WriteFile(filepath.Join(userDir, "kdeglobals"), []byte("[Icons]\nTheme=Papirus\n\n[General]\nColorScheme=BreezeDark\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	c, err := readKConfigCascade("kdeglobals")
+	test.RequireNoError(t, err)
+
+	if value, _ := c.get("Icons", "Theme"); value != "corporate" {
+		t.Errorf("got icon theme %q; want the immutable system value", value)
+	}
+
+	if value, _ := c.get("General", "ColorScheme"); value != "BreezeDark" {
+		t.Errorf("got color scheme %q; want the user value", value)
+	}
+
+	if path := c.lockedBy("Icons", "Theme"); path != filepath.Join(systemDir, "kdeglobals") {
+		t.Errorf("got locked by %q", path)
+	}
+
+	if path := c.lockedBy("General", "ColorScheme"); path != "" {
+		t.Errorf("got locked by %q; want unlocked", path)
+	}
+}
+
+func TestKConfigSetEscapes(t *testing.T) {
+	values := []string{`C:\path\to`, " leading", "trailing ", "two\nlines", "tab\there", "plain value"}
+
+	k := parseKConfig(nil)
+	for i, value := range values {
+		k.set("Group", fmt.Sprintf("key%d", i), value)
+	}
+
+	reparsed := parseKConfig(k.bytes())
+
+	for i, want := range values {
+		got, ok := reparsed.get("Group", fmt.Sprintf("key%d", i))
+		if !ok || got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	}
+}
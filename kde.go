@@ -0,0 +1,350 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Plasma's defaults, reported when a key has never been written.
+const (
+	kdeDefaultGtkTheme    = "Breeze"
+	kdeDefaultIconTheme   = "breeze"
+	kdeDefaultCursorTheme = "breeze_cursors"
+	kdeDefaultCursorSize  = 24
+	kdeDefaultLightScheme = "BreezeLight"
+	kdeDefaultDarkScheme  = "BreezeDark"
+)
+
+// kdeBackend edits the KConfig files Plasma reads its look from. The GTK
+// theme is owned by kde-gtk-config, which keeps it in the GTK settings.ini
+// and gtkrc files.
+type kdeBackend struct{}
+
+// kdeSetting is a single KConfig key write.
+type kdeSetting struct {
+	file  string
+	group string
+	key   string
+	value string
+}
+
+func newKdeBackend() backend {
+	return &kdeBackend{}
+}
+
+func (k *kdeBackend) name() string {
+	return "kde"
+}
+
+func (k *kdeBackend) available() bool {
+	return isFile(getKConfigPath("kdeglobals")) || commandExists("plasmashell")
+}
+
+func (k *kdeBackend) capabilities() capability {
+	return capGtkTheme | capIconTheme | capCursorTheme | capCursorSize | capColorScheme
+}
+
+func (k *kdeBackend) read() (themeConfig, error) {
+	globals, err := readKConfigCascade("kdeglobals")
+	if err != nil {
+		return themeConfig{}, err
+	}
+
+	input, err := readKConfigCascade("kcminputrc")
+	if err != nil {
+		return themeConfig{}, err
+	}
+
+	cfg := themeConfig{
+		gtkTheme:    kdeDefaultGtkTheme,
+		iconTheme:   kdeDefaultIconTheme,
+		cursorTheme: kdeDefaultCursorTheme,
+		cursorSize:  kdeDefaultCursorSize,
+	}
+
+	settings, err := os.ReadFile(filepath.Join(getConfigDir(), "gtk-3.0", "settings.ini"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return themeConfig{}, fmt.Errorf("failed to read gtk theme information: %w", err)
+	}
+
+	if value, ok := parseINI(settings).get("Settings", "gtk-theme-name"); ok && value != "" {
+		cfg.gtkTheme = value
+	}
+
+	if value, ok := globals.get("Icons", "Theme"); ok && value != "" {
+		cfg.iconTheme = value
+	}
+
+	if value, ok := input.get("Mouse", "cursorTheme"); ok && value != "" {
+		cfg.cursorTheme = value
+	}
+
+	if value, ok := input.get("Mouse", "cursorSize"); ok {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			cfg.cursorSize = size
+		}
+	}
+
+	cfg.preferDark = isDarkKdeScheme(globals)
+
+	return cfg, nil
+}
+
+func (k *kdeBackend) write(cfg themeConfig) error {
+	globals, err := readKConfigCascade("kdeglobals")
+	if err != nil {
+		return err
+	}
+
+	settings := []kdeSetting{
+		{"kdeglobals", "Icons", "Theme", cfg.iconTheme},
+		{"kcminputrc", "Mouse", "cursorTheme", cfg.cursorTheme},
+	}
+
+	if cfg.cursorSize > 0 {
+		settings = append(settings, kdeSetting{"kcminputrc", "Mouse", "cursorSize", strconv.Itoa(cfg.cursorSize)})
+	}
+
+	// keep the user's scheme unless it is on the wrong side of dark/light
+	if isDarkKdeScheme(globals) != cfg.preferDark {
+		current, _ := globals.get("General", "ColorScheme")
+
+		scheme, err := getKdeColorScheme(current, cfg.preferDark)
+		if err != nil {
+			return err
+		}
+
+		settings = append(settings, kdeSetting{"kdeglobals", "General", "ColorScheme", scheme})
+
+		colors, err := getKdeSchemeColors(scheme)
+		if err != nil {
+			return err
+		}
+
+		settings = append(settings, colors...)
+	}
+
+	if err := writeKdeSettings(settings); err != nil {
+		return err
+	}
+
+	// kde-gtk-config's own gtkrc files, which Plasma adds to GTK2_RC_FILES
+	for _, name := range []string{"gtkrc", "gtkrc-2.0"} {
+		path := filepath.Join(getConfigDir(), name)
+
+		old, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if err := writeConfigFile(path, renderGtkrc(old, cfg)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// writeKdeSettings applies settings to the user's config files, refusing
+// keys that an administrator locked with [$i].
+func writeKdeSettings(settings []kdeSetting) error {
+	files := []string{}
+	grouped := map[string][]kdeSetting{}
+
+	for _, s := range settings {
+		if _, ok := grouped[s.file]; !ok {
+			files = append(files, s.file)
+		}
+
+		grouped[s.file] = append(grouped[s.file], s)
+	}
+
+	for _, file := range files {
+		cascade, err := readKConfigCascade(file)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range grouped[file] {
+			if path := cascade.lockedBy(s.group, s.key); path != "" {
+				return fmt.Errorf("cannot set [%s] %s in %s: it is marked immutable in %s", s.group, s.key, file, path)
+			}
+		}
+
+		path := getKConfigPath(file)
+
+		old, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		k := parseKConfig(old)
+
+		for _, s := range grouped[file] {
+			k.set(s.group, s.key, s.value)
+		}
+
+		if err := writeConfigFile(path, k.bytes()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// findKdeColorScheme returns the path of an installed .colors file.
+func findKdeColorScheme(name string) string {
+	for _, dir := range getDataDirs() {
+		path := filepath.Join(dir, "color-schemes", name+".colors")
+		if isFile(path) {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// getKdeSchemeColors returns the color groups of an installed scheme.
+// Plasma copies them into kdeglobals when a scheme is applied, and KDE
+// applications read their colors from there rather than from the scheme.
+func getKdeSchemeColors(name string) ([]kdeSetting, error) {
+	path := findKdeColorScheme(name)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read color scheme %s: %w", path, err)
+	}
+
+	settings := []kdeSetting{}
+
+	for _, line := range parseKConfig(data).ini.lines {
+		if line.kind == iniKey && strings.HasPrefix(line.section, "Colors:") {
+			settings = append(settings, kdeSetting{"kdeglobals", line.section, line.key, unescapeDesktopValue(line.value)})
+		}
+	}
+
+	return settings, nil
+}
+
+// isDarkKdeScheme decides whether the active color scheme is dark from its
+// window background, read from the installed .colors file. Schemes that
+// are not installed are judged by name, and as a last resort by the colors
+// Plasma copied into kdeglobals.
+func isDarkKdeScheme(globals *kconfigCascade) bool {
+	scheme, ok := globals.get("General", "ColorScheme")
+	if !ok || scheme == "" {
+		scheme = kdeDefaultLightScheme
+	}
+
+	if path := findKdeColorScheme(scheme); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			if value, ok := parseKConfig(data).get("Colors:Window", "BackgroundNormal"); ok {
+				if dark, ok := isDarkKdeColor(value); ok {
+					return dark
+				}
+			}
+		}
+	}
+
+	lname := strings.ToLower(scheme)
+
+	if strings.Contains(lname, "dark") || strings.Contains(lname, "light") {
+		return strings.Contains(lname, "dark")
+	}
+
+	if value, ok := globals.get("Colors:Window", "BackgroundNormal"); ok {
+		if dark, ok := isDarkKdeColor(value); ok {
+			return dark
+		}
+	}
+
+	return false
+}
+
+// isDarkKdeColor parses an "r,g,b" KConfig color and reports whether its
+// relative luminance is below the middle grey.
+func isDarkKdeColor(value string) (bool, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) < 3 {
+		return false, false
+	}
+
+	channels := [3]float64{}
+
+	for i := range channels {
+		n, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil || n < 0 || n > 255 {
+			return false, false
+		}
+
+		channels[i] = float64(n) / 255
+	}
+
+	luminance := 0.2126*channels[0] + 0.7152*channels[1] + 0.0722*channels[2]
+
+	return luminance < 0.5, true
+}
+
+// getKdeColorScheme returns the scheme for the dark or light side: the one
+// chosen in lookctl.conf, or else a guessed counterpart of current.
+func getKdeColorScheme(current string, dark bool) (string, error) {
+	conf, err := readLookctlConfig()
+	if err != nil {
+		return "", err
+	}
+
+	key := "light-color-scheme"
+	if dark {
+		key = "dark-color-scheme"
+	}
+
+	scheme, ok := conf.get("kde", key)
+	if !ok || scheme == "" {
+		return getKdeColorSchemeCounterpart(current, dark), nil
+	}
+
+	if findKdeColorScheme(scheme) == "" {
+		return "", fmt.Errorf("color scheme '%s' set as %s in %s is not installed", scheme, key, getLookctlConfigPath())
+	}
+
+	return scheme, nil
+}
+
+// getKdeColorSchemeCounterpart maps a scheme onto its dark or light
+// variant, such as "MateriaLight" to "MateriaDark", falling back to the
+// Breeze schemes when no installed counterpart exists.
+func getKdeColorSchemeCounterpart(current string, dark bool) string {
+	from, to, fallback := "Dark", "Light", kdeDefaultLightScheme
+	if dark {
+		from, to, fallback = "Light", "Dark", kdeDefaultDarkScheme
+	}
+
+	candidates := []string{}
+
+	if strings.Contains(current, from) {
+		candidates = append(candidates, strings.Replace(current, from, to, 1))
+	}
+
+	if current != "" {
+		candidates = append(candidates, current+to)
+	}
+
+	for _, candidate := range candidates {
+		if findKdeColorScheme(candidate) != "" {
+			return candidate
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func setupKdeConfig(t testing.TB) (string, string) {
+	t.Helper()
+
+	schemeDir := setupAssetDir(t, "color-schemes")
+
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "config")
+
+	t.Setenv(envConfigHome, configDir)
+	t.Setenv(envXdgConfigDirs, filepath.Join(tempDir, "etc", "xdg"))
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+
+	test.CreateEmptyDir(t, configDir)
+
+	return configDir, schemeDir
+}
+
+func TestKdeBackendReadDefaults(t *testing.T) {
+	setupKdeConfig(t)
+
+	got, err := (&kdeBackend{}).read()
+	test.RequireNoError(t, err)
+
+	want := themeConfig{
+		gtkTheme:    kdeDefaultGtkTheme,
+		iconTheme:   kdeDefaultIconTheme,
+		cursorTheme: kdeDefaultCursorTheme,
+		cursorSize:  kdeDefaultCursorSize,
+	}
+
+	if got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestKdeBackendWrite(t *testing.T) {
+	configDir, schemeDir := setupKdeConfig(t)
+
+	err := os.WriteFile(filepath.Join(schemeDir, "BreezeDark.colors"), []byte("[General]\nName=Breeze Dark\n\n[Colors:Window]\nBackgroundNormal=32,35,38\nForegroundNormal=252,252,252\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	err = os.WriteFile(filepath.Join(configDir, "kdeglobals"), []byte("[General]\nColorScheme=BreezeLight\nfixed=Hack,10\n\n[Colors:Window]\nBackgroundNormal=239,240,241\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	err = os.WriteFile(filepath.Join(configDir, "gtkrc-2.0"), []byte("gtk-theme-name=\"Breeze\"\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	cfg := themeConfig{
+		gtkTheme:    kdeDefaultGtkTheme,
+		iconTheme:   "Papirus",
+		cursorTheme: "Bibata",
+		cursorSize:  32,
+		preferDark:  true,
+	}
+
+	b := &kdeBackend{}
+	test.RequireNoError(t, b.write(cfg))
+
+	got, err := b.read()
+	test.RequireNoError(t, err)
+
+	if got != cfg {
+		t.Errorf("got %+v; want %+v", got, cfg)
+	}
+
+	globals, err := os.ReadFile(filepath.Join(configDir, "kdeglobals"))
+	test.RequireNoError(t, err)
+
+	want := "[General]\nColorScheme=BreezeDark\nfixed=Hack,10\n\n[Colors:Window]\nBackgroundNormal=32,35,38\nForegroundNormal=252,252,252\n\n[Icons]\nTheme=Papirus\n"
+	if string(globals) != want {
+		t.Errorf("got kdeglobals %q; want %q", globals, want)
+	}
+
+	input, err := os.ReadFile(filepath.Join(configDir, "kcminputrc"))
+	test.RequireNoError(t, err)
+
+	if string(input) != "[Mouse]\ncursorTheme=Bibata\ncursorSize=32\n" {
+		t.Errorf("got kcminputrc %q", input)
+	}
+
+	gtkrc, err := os.ReadFile(filepath.Join(configDir, "gtkrc-2.0"))
+	test.RequireNoError(t, err)
+
+	if !strings.Contains(string(gtkrc), "gtk-icon-theme-name=\"Papirus\"") {
+		t.Errorf("gtkrc-2.0 not updated: %q", gtkrc)
+	}
+
+	if isFile(filepath.Join(configDir, "gtkrc")) {
+		t.Error("created a gtkrc that did not exist")
+	}
+}
+
+func TestKdeBackendWriteImmutable(t *testing.T) {
+	setupKdeConfig(t)
+
+	systemDir := os.Getenv(envXdgConfigDirs)
+	test.CreateEmptyDir(t, systemDir)
+
+	err := os.WriteFile(filepath.Join(systemDir, "kcminputrc"), []byte("[Mouse][$i]\ncursorTheme=breeze_cursors\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	err = (&kdeBackend{}).write(themeConfig{iconTheme: "Papirus", cursorTheme: "Bibata"})
+	if err == nil || !strings.Contains(err.Error(), "immutable") {
+		t.Errorf("got %v; want an immutable key error", err)
+	}
+}
+
+func TestGetKdeColorSchemeCounterpart(t *testing.T) {
+	schemeDir := setupAssetDir(t, "color-schemes")
+
+	test.CreateEmptyFile(t, filepath.Join(schemeDir, "MateriaDark.colors"))
+	test.CreateEmptyFile(t, filepath.Join(schemeDir, "NordLight.colors"))
+
+	tests := []struct {
+		current string
+		dark    bool
+		want    string
+	}{
+		{"MateriaLight", true, "MateriaDark"},
+		{"Nord", false, "NordLight"},
+		{"Custom", true, kdeDefaultDarkScheme},
+		{"", false, kdeDefaultLightScheme},
+	}
+
+	for _, tt := range tests {
+		if got := getKdeColorSchemeCounterpart(tt.current, tt.dark); got != tt.want {
+			t.Errorf("getKdeColorSchemeCounterpart(%q, %t) = %q; want %q", tt.current, tt.dark, got, tt.want)
+		}
+	}
+}
+
+func TestKdeBackendWriteConfiguredScheme(t *testing.T) {
+	configDir, schemeDir := setupKdeConfig(t)
+
+	for _, name := range []string{"BreezeDark", "Nordic", "NordicLight"} {
+		err := os.WriteFile(filepath.Join(schemeDir, name+".colors"), []byte("[Colors:Window]\nBackgroundNormal=46,52,64\n"), 0o644)
+		test.RequireNoError(t, err)
+	}
+
+	test.CreateEmptyDir(t, filepath.Join(configDir, "lookctl"))
+
+	err := os.WriteFile(getLookctlConfigPath(), []byte("[kde]\ndark-color-scheme=Nordic\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	b := &kdeBackend{}
+	test.RequireNoError(t, b.write(themeConfig{iconTheme: "Papirus", cursorTheme: "Bibata", preferDark: true}))
+
+	globals, err := readKConfigCascade("kdeglobals")
+	test.RequireNoError(t, err)
+
+	if scheme, _ := globals.get("General", "ColorScheme"); scheme != "Nordic" {
+		t.Errorf("got color scheme %q; want %q", scheme, "Nordic")
+	}
+
+	err = os.WriteFile(getLookctlConfigPath(), []byte("[kde]\ndark-color-scheme=Missing\n"), 0o644)
+	test.RequireNoError(t, err)
+
+	test.RequireNoError(t, os.Remove(filepath.Join(configDir, "kdeglobals")))
+
+	err = b.write(themeConfig{iconTheme: "Papirus", cursorTheme: "Bibata", preferDark: true})
+	if err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("got %v; want an error for a scheme that is not installed", err)
+	}
+}
@@ -5,58 +5,87 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 )
 
-const gnomeDesktopInterface = "org.gnome.desktop.interface"
-
 type themeConfig struct {
 	gtkTheme    string
 	iconTheme   string
 	cursorTheme string
+	cursorSize  int
 	preferDark  bool
 }
 
-func getCurrentTheme() (themeConfig, error) {
-	gtkTheme, err := getGsettingsValue(gnomeDesktopInterface, "gtk-theme")
-	if err != nil {
-		return themeConfig{}, fmt.Errorf("failed to read gtk theme information: %w", err)
-	}
+// themeRecord is the serialized form of themeConfig used by history and
+// profiles.
+type themeRecord struct {
+	GTK         string `json:"gtk"`
+	Icon        string `json:"icon"`
+	Cursor      string `json:"cursor"`
+	CursorSize  int    `json:"cursor_size,omitempty"`
+	ColorScheme string `json:"color_scheme"`
+}
 
-	iconTheme, err := getGsettingsValue(gnomeDesktopInterface, "icon-theme")
-	if err != nil {
-		return themeConfig{}, fmt.Errorf("failed to read icon theme information: %w", err)
+func (cfg themeConfig) colorScheme() string {
+	if cfg.preferDark {
+		return "dark"
 	}
 
-	cursorTheme, err := getGsettingsValue(gnomeDesktopInterface, "cursor-theme")
-	if err != nil {
-		return themeConfig{}, fmt.Errorf("failed to read cursor theme information: %w", err)
-	}
+	return "light"
+}
 
-	colorScheme, err := getGsettingsValue(gnomeDesktopInterface, "color-scheme")
-	if err != nil {
-		return themeConfig{}, fmt.Errorf("failed to read color scheme information: %w", err)
+func (cfg themeConfig) record() themeRecord {
+	return themeRecord{
+		GTK:         cfg.gtkTheme,
+		Icon:        cfg.iconTheme,
+		Cursor:      cfg.cursorTheme,
+		CursorSize:  cfg.cursorSize,
+		ColorScheme: cfg.colorScheme(),
 	}
+}
 
-	preferDark := false
-	if colorScheme == "prefer-dark" {
-		preferDark = true
+func (r themeRecord) config() themeConfig {
+	return themeConfig{
+		gtkTheme:    r.GTK,
+		iconTheme:   r.Icon,
+		cursorTheme: r.Cursor,
+		cursorSize:  r.CursorSize,
+		preferDark:  r.ColorScheme == "dark",
 	}
+}
 
-	return themeConfig{
-		gtkTheme:    gtkTheme,
-		iconTheme:   iconTheme,
-		cursorTheme: cursorTheme,
-		preferDark:  preferDark,
-	}, nil
+const (
+	assetGtk    = "gtk"
+	assetIcon   = "icon"
+	assetCursor = "cursor"
+)
+
+type asset struct {
+	name     string
+	path     string
+	kind     string
+	shadowed bool
 }
 
 func getInstalledThemes() []string {
+	return getAssetNames(findInstalledThemes())
+}
+
+func getInstalledIconThemes() []string {
+	return getAssetNames(findInstalledIconThemes())
+}
+
+func getInstalledCursorThemes() []string {
+	return getAssetNames(findInstalledCursorThemes())
+}
+
+func findInstalledThemes() []asset {
 	themeSearchPaths := getAssetSearchPaths("themes", ".themes")
 
 	excluded := []string{"Default", "Emacs"}
 
-	themeList := getAssets(themeSearchPaths, func(fullPath, name string) bool {
+	themeList := findAssets(themeSearchPaths, assetGtk, func(fullPath, name string) bool {
 		if slices.Contains(excluded, name) {
 			return false
 		}
@@ -64,12 +93,12 @@ func getInstalledThemes() []string {
 		return isFile(filepath.Join(fullPath, "index.theme"))
 	})
 
-	slices.Sort(themeList)
+	sortAssets(themeList)
 
 	return themeList
 }
 
-func getInstalledIconThemes() []string {
+func findInstalledIconThemes() []asset {
 	iconSearchPaths := getAssetSearchPaths("icons", ".icons")
 
 	mustContainOneOf := []string{
@@ -82,7 +111,7 @@ func getInstalledIconThemes() []string {
 
 	excluded := []string{"hicolor", "locolor", "default", "gnome"}
 
-	iconList := getAssets(iconSearchPaths, func(fullPath, name string) bool {
+	iconList := findAssets(iconSearchPaths, assetIcon, func(fullPath, name string) bool {
 		if slices.Contains(excluded, name) {
 			return false
 		}
@@ -103,25 +132,29 @@ func getInstalledIconThemes() []string {
 		})
 	})
 
-	slices.Sort(iconList)
+	sortAssets(iconList)
 
 	return iconList
 }
 
-func getInstalledCursorThemes() []string {
+func findInstalledCursorThemes() []asset {
 	cursorSearchPaths := getAssetSearchPaths("icons", ".icons")
 
 	excluded := []string{"default"}
 
-	cursorList := getAssets(cursorSearchPaths, func(fullPath, name string) bool {
+	cursorList := findAssets(cursorSearchPaths, assetCursor, func(fullPath, name string) bool {
 		if slices.Contains(excluded, name) {
 			return false
 		}
 
+		if isHyprcursorTheme(fullPath) {
+			return true
+		}
+
 		return isFile(filepath.Join(fullPath, "index.theme")) && isDir(filepath.Join(fullPath, "cursors"))
 	})
 
-	slices.Sort(cursorList)
+	sortAssets(cursorList)
 
 	return cursorList
 }
@@ -162,13 +195,26 @@ func setTheme(cfg *themeConfig, themeName string) error {
 	return nil
 }
 
-func setIconTheme(cfg *themeConfig, themeName string) error {
+func setIconTheme(cfg *themeConfig, themeName string, force bool) error {
 	installedIconThemes := getInstalledIconThemes()
 
 	if !slices.Contains(installedIconThemes, themeName) {
 		return fmt.Errorf("icon theme not found. see 'lookctl list -icon' for list available themes")
 	}
 
+	_, problems, err := checkIconThemeInheritance(themeName)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 && !force {
+		return fmt.Errorf("icon theme '%s' has a broken inheritance chain: %s. use -force to apply it anyway", themeName, strings.Join(problems, "; "))
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", problem)
+	}
+
 	cfg.iconTheme = themeName
 
 	return nil
@@ -186,6 +232,36 @@ func setCursorTheme(cfg *themeConfig, themeName string) error {
 	return nil
 }
 
+// setCursorSize only accepts sizes the cursor theme actually ships, since
+// Xcursor would otherwise silently pick the closest available size.
+func setCursorSize(cfg *themeConfig, size int) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid cursor size. must be a positive number")
+	}
+
+	info, err := getCursorThemeInfo(cfg.cursorTheme)
+	if err != nil {
+		return err
+	}
+
+	if len(info.Sizes) == 0 {
+		return fmt.Errorf("cursor theme '%s' has no readable Xcursor files", cfg.cursorTheme)
+	}
+
+	if !slices.Contains(info.Sizes, size) {
+		sizes := []string{}
+		for _, s := range info.Sizes {
+			sizes = append(sizes, strconv.Itoa(s))
+		}
+
+		return fmt.Errorf("cursor size %d is not available in '%s'. available sizes: %s", size, cfg.cursorTheme, strings.Join(sizes, ", "))
+	}
+
+	cfg.cursorSize = size
+
+	return nil
+}
+
 func setColorScheme(cfg *themeConfig, colorScheme string) error {
 	switch colorScheme {
 	case "dark":
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -200,3 +201,34 @@ func setupAssetDir(t testing.TB, assetName string) string {
 
 	return assetPath
 }
+
+func TestSetCursorSize(t *testing.T) {
+	iconDirPath := setupAssetDir(t, "icons")
+
+	themePath := filepath.Join(iconDirPath, "Bibata")
+	cursorsPath := filepath.Join(themePath, "cursors")
+
+	test.CreateEmptyDir(t, cursorsPath)
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	data := encodeTestXcursor([]xcursorImage{newTestXcursorImage(24, 0), newTestXcursorImage(48, 0)})
+	test.RequireNoError(t, os.WriteFile(filepath.Join(cursorsPath, "left_ptr"), data, 0o644))
+
+	cfg := themeConfig{cursorTheme: "Bibata"}
+
+	test.RequireNoError(t, setCursorSize(&cfg, 48))
+
+	if cfg.cursorSize != 48 {
+		t.Errorf("got cursor size %d; want 48", cfg.cursorSize)
+	}
+
+	for _, size := range []int{32, 0, -24} {
+		if err := setCursorSize(&cfg, size); err == nil {
+			t.Errorf("expected error for size %d", size)
+		}
+	}
+
+	if cfg.cursorSize != 48 {
+		t.Errorf("cursor size changed on error: %d", cfg.cursorSize)
+	}
+}
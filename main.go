@@ -5,6 +5,11 @@ import (
 	"os"
 )
 
+type globalOptions struct {
+	backend string
+	format  string
+}
+
 func main() {
 	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
@@ -15,10 +20,17 @@ func main() {
 func run(args []string) error {
 	fs := newFlagSet("lookctl")
 
+	backendName := fs.String("backend", "", "Force a settings backend")
+	format := fs.String("format", formatText, "Output format")
+
 	if err := parseFlag(fs, args, printMainHelp); err != nil {
 		return err
 	}
 
+	if err := validateFormat(*format); err != nil {
+		return err
+	}
+
 	tw := newTabWriter(os.Stderr)
 
 	if fs.NArg() == 0 {
@@ -26,17 +38,42 @@ func run(args []string) error {
 		return fmt.Errorf("please specify a command")
 	}
 
+	opts := globalOptions{
+		backend: *backendName,
+		format:  *format,
+	}
+
 	cmd := fs.Arg(0)
 	cmdArgs := fs.Args()[1:]
 
 	var err error
 	switch cmd {
 	case "list":
-		err = list(cmdArgs)
+		err = list(opts, cmdArgs)
+	case "check":
+		err = check(opts, cmdArgs)
 	case "current":
-		err = current(cmdArgs)
+		err = current(opts, cmdArgs)
+	case "icon":
+		err = icon(opts, cmdArgs)
+	case "info":
+		err = info(opts, cmdArgs)
 	case "set":
-		err = set(cmdArgs)
+		err = set(opts, cmdArgs)
+	case "profile":
+		err = profile(opts, cmdArgs)
+	case "cursor":
+		err = cursor(opts, cmdArgs)
+	case "history":
+		err = history(cmdArgs)
+	case "undo":
+		err = undo(opts, cmdArgs)
+	case "redo":
+		err = redo(opts, cmdArgs)
+	case "restore-backup":
+		err = restoreBackups(cmdArgs)
+	case "backends":
+		err = listBackends(opts, cmdArgs)
 	default:
 		return fmt.Errorf("unknown command: '%s'. see 'lookctl -h' for more information", cmd)
 	}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+var outputFormats = []string{formatText, formatJSON, formatYAML}
+
+// currentView and assetView are the stable schema of 'current' and 'list'
+// in the structured output formats and the data passed to templates.
+type currentView struct {
+	Backend     string `json:"backend"`
+	GTK         string `json:"gtk"`
+	Icon        string `json:"icon"`
+	Cursor      string `json:"cursor"`
+	CursorSize  int    `json:"cursor_size"`
+	ColorScheme string `json:"color_scheme"`
+	GTKPath     string `json:"gtk_path"`
+	IconPath    string `json:"icon_path"`
+	CursorPath  string `json:"cursor_path"`
+}
+
+type assetView struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Shadowed bool   `json:"shadowed"`
+}
+
+func newCurrentView(b backend, cfg themeConfig) currentView {
+	return currentView{
+		Backend:     b.name(),
+		GTK:         cfg.gtkTheme,
+		Icon:        cfg.iconTheme,
+		Cursor:      cfg.cursorTheme,
+		CursorSize:  cfg.cursorSize,
+		ColorScheme: cfg.colorScheme(),
+		GTKPath:     getAssetPath(findInstalledThemes(), cfg.gtkTheme),
+		IconPath:    getAssetPath(findInstalledIconThemes(), cfg.iconTheme),
+		CursorPath:  getAssetPath(findInstalledCursorThemes(), cfg.cursorTheme),
+	}
+}
+
+func getAssetPath(assets []asset, name string) string {
+	for _, a := range assets {
+		if a.name == name && !a.shadowed {
+			return a.path
+		}
+	}
+
+	return ""
+}
+
+func newAssetViews(assets []asset) []assetView {
+	views := make([]assetView, 0, len(assets))
+
+	for _, a := range assets {
+		views = append(views, assetView{Name: a.name, Path: a.path, Kind: a.kind, Shadowed: a.shadowed})
+	}
+
+	return views
+}
+
+func validateFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("invalid format: '%s'. must be one of %s", format, strings.Join(outputFormats, ", "))
+	}
+
+	return nil
+}
+
+func writeStructured(w io.Writer, format string, v any) error {
+	switch format {
+	case formatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "%s\n", data)
+
+		return err
+	case formatYAML:
+		_, err := w.Write(marshalYAML(v))
+
+		return err
+	}
+
+	return fmt.Errorf("format '%s' is not a structured format", format)
+}
+
+func parseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// writeTemplate executes tmpl with data, ending the output with a newline
+// so every execution yields exactly one line unless the template spans more.
+func writeTemplate(w io.Writer, tmpl *template.Template, data any) error {
+	var sb strings.Builder
+
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	out := sb.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+
+	_, err := io.WriteString(w, out)
+
+	return err
+}
+
+// marshalYAML encodes the subset of values lookctl emits: structs (using
+// their json tags), slices, string keyed maps and scalars.
+func marshalYAML(v any) []byte {
+	var sb strings.Builder
+
+	writeYAML(&sb, reflect.ValueOf(v), 0, false)
+
+	return []byte(sb.String())
+}
+
+type yamlField struct {
+	key   string
+	value reflect.Value
+}
+
+func writeYAML(sb *strings.Builder, v reflect.Value, indent int, inline bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			sb.WriteString("null\n")
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	pad := strings.Repeat(" ", indent)
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		fields := getYAMLFields(v)
+		if len(fields) == 0 {
+			sb.WriteString("{}\n")
+			return
+		}
+
+		for i, field := range fields {
+			if i > 0 || !inline {
+				sb.WriteString(pad)
+			}
+
+			sb.WriteString(yamlString(field.key) + ":")
+
+			if isYAMLScalar(field.value) {
+				sb.WriteString(" ")
+				writeYAML(sb, field.value, indent+2, true)
+			} else {
+				sb.WriteString("\n")
+				writeYAML(sb, field.value, indent+2, false)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			sb.WriteString("[]\n")
+			return
+		}
+
+		for i := range v.Len() {
+			if i > 0 || !inline {
+				sb.WriteString(pad)
+			}
+
+			sb.WriteString("- ")
+			writeYAML(sb, v.Index(i), indent+2, true)
+		}
+	default:
+		sb.WriteString(yamlScalar(v) + "\n")
+	}
+}
+
+func isYAMLScalar(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		return len(getYAMLFields(v)) == 0
+	case reflect.Slice, reflect.Array:
+		return v.Len() == 0
+	}
+
+	return true
+}
+
+func getYAMLFields(v reflect.Value) []yamlField {
+	fields := []yamlField{}
+
+	if v.Kind() == reflect.Map {
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+
+		for _, key := range keys {
+			fields = append(fields, yamlField{key: key.String(), value: v.MapIndex(key)})
+		}
+
+		return fields
+	}
+
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		if strings.Contains(opts, "omitempty") && v.Field(i).IsZero() {
+			continue
+		}
+
+		fields = append(fields, yamlField{key: name, value: v.Field(i)})
+	}
+
+	return fields
+}
+
+var yamlPlainString = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9_./ ()+-]*$`)
+
+var yamlReserved = []string{"true", "false", "yes", "no", "on", "off", "null", "y", "n"}
+
+func yamlScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return yamlString(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+
+	return yamlString(fmt.Sprint(v.Interface()))
+}
+
+func yamlString(s string) string {
+	if yamlPlainString.MatchString(s) && !strings.HasSuffix(s, " ") && !slices.Contains(yamlReserved, strings.ToLower(s)) {
+		return s
+	}
+
+	return strconv.Quote(s)
+}
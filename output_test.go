@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	tests := []struct {
+		description string
+		value       any
+		want        string
+	}{
+		{
+			description: "struct uses json field names",
+			value: currentView{
+				Backend:     "gsettings",
+				GTK:         "Adwaita-dark",
+				Icon:        "",
+				Cursor:      "true",
+				ColorScheme: "dark",
+			},
+			want: "backend: gsettings\ngtk: Adwaita-dark\nicon: \"\"\ncursor: \"true\"\ncursor_size: 0\ncolor_scheme: dark\ngtk_path: \"\"\nicon_path: \"\"\ncursor_path: \"\"\n",
+		},
+		{
+			description: "slice of structs",
+			value: []assetView{
+				{Name: "Adwaita", Path: "/usr/share/themes/Adwaita", Kind: "gtk"},
+				{Name: "Nord: Frost", Path: "/home/me/.themes/Nord: Frost", Kind: "gtk", Shadowed: true},
+			},
+			want: "- name: Adwaita\n  path: /usr/share/themes/Adwaita\n  kind: gtk\n  shadowed: false\n- name: \"Nord: Frost\"\n  path: \"/home/me/.themes/Nord: Frost\"\n  kind: gtk\n  shadowed: true\n",
+		},
+		{
+			description: "empty slice",
+			value:       []assetView{},
+			want:        "[]\n",
+		},
+		{
+			description: "nested collections",
+			value: map[string]any{
+				"sizes": []int{24, 32},
+				"theme": struct {
+					Name string `json:"name"`
+				}{Name: "Bibata"},
+			},
+			want: "sizes:\n  - 24\n  - 32\ntheme:\n  name: Bibata\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got := string(marshalYAML(tt.value))
+
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	tests := []struct {
+		description string
+		template    string
+		data        any
+		want        string
+	}{
+		{
+			description: "appends newline",
+			template:    "{{.GTK}} / {{.Icon}}",
+			data:        currentView{GTK: "Adwaita", Icon: "Papirus"},
+			want:        "Adwaita / Papirus\n",
+		},
+		{
+			description: "keeps trailing newline",
+			template:    "{{.Name}}\t{{.Path}}\n",
+			data:        assetView{Name: "Adwaita", Path: "/usr/share/themes/Adwaita"},
+			want:        "Adwaita\t/usr/share/themes/Adwaita\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			tmpl, err := parseOutputTemplate(tt.template)
+			test.RequireNoError(t, err)
+
+			var sb strings.Builder
+
+			err = writeTemplate(&sb, tmpl, tt.data)
+			test.RequireNoError(t, err)
+
+			if sb.String() != tt.want {
+				t.Errorf("got %q; want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTemplateUnknownField(t *testing.T) {
+	tmpl, err := parseOutputTemplate("{{.Nope}}")
+	test.RequireNoError(t, err)
+
+	var sb strings.Builder
+
+	if err := writeTemplate(&sb, tmpl, currentView{}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
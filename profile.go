@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const historyProfile = "profile"
+
+func getProfileDir() string {
+	return filepath.Join(getConfigDir(), "lookctl", "profiles")
+}
+
+func getProfilePath(name string) string {
+	return filepath.Join(getProfileDir(), name+".json")
+}
+
+func validateProfileName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name: '%s'", name)
+	}
+
+	return nil
+}
+
+func saveProfile(name string, cfg themeConfig) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg.record(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(getProfileDir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	if err := writeFileAtomic(getProfilePath(name), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to save profile '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// loadProfile returns the record rather than a themeConfig, so fields the
+// profile leaves empty can be told apart from a light color scheme.
+func loadProfile(name string) (themeRecord, error) {
+	if err := validateProfileName(name); err != nil {
+		return themeRecord{}, err
+	}
+
+	data, err := os.ReadFile(getProfilePath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return themeRecord{}, fmt.Errorf("profile '%s' not found. see 'lookctl profile list' for saved profiles", name)
+		}
+
+		return themeRecord{}, fmt.Errorf("failed to read profile '%s': %w", name, err)
+	}
+
+	var record themeRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return themeRecord{}, fmt.Errorf("failed to parse profile '%s': %w", name, err)
+	}
+
+	return record, nil
+}
+
+// mergeProfile sets the components a profile defines on cfg, with the same
+// validation as 'set'. Empty fields, which backends that cannot report a
+// setting save, keep the current value.
+func mergeProfile(cfg *themeConfig, r themeRecord, force bool) error {
+	if r.GTK != "" {
+		if err := setTheme(cfg, r.GTK); err != nil {
+			return err
+		}
+	}
+
+	if r.Icon != "" {
+		if err := setIconTheme(cfg, r.Icon, force); err != nil {
+			return err
+		}
+	}
+
+	if r.Cursor != "" {
+		if err := setCursorTheme(cfg, r.Cursor); err != nil {
+			return err
+		}
+	}
+
+	if r.CursorSize != 0 {
+		if err := setCursorSize(cfg, r.CursorSize); err != nil {
+			return err
+		}
+	}
+
+	if r.ColorScheme != "" {
+		if err := setColorScheme(cfg, r.ColorScheme); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(getProfilePath(name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("profile '%s' not found. see 'lookctl profile list' for saved profiles", name)
+		}
+
+		return fmt.Errorf("failed to delete profile '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+func listProfiles() ([]string, error) {
+	entries, err := os.ReadDir(getProfileDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to read profile directory: %w", err)
+	}
+
+	names := []string{}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || validateProfileName(name) != nil {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// getMissingComponents returns a description of every component of cfg that
+// is not installed on this system.
+func getMissingComponents(cfg themeConfig) []string {
+	missing := []string{}
+
+	if cfg.gtkTheme != "" && !slices.Contains(getInstalledThemes(), cfg.gtkTheme) {
+		missing = append(missing, fmt.Sprintf("gtk theme '%s'", cfg.gtkTheme))
+	}
+
+	if cfg.iconTheme != "" && !slices.Contains(getInstalledIconThemes(), cfg.iconTheme) {
+		missing = append(missing, fmt.Sprintf("icon theme '%s'", cfg.iconTheme))
+	}
+
+	if cfg.cursorTheme != "" && !slices.Contains(getInstalledCursorThemes(), cfg.cursorTheme) {
+		missing = append(missing, fmt.Sprintf("cursor theme '%s'", cfg.cursorTheme))
+	}
+
+	return missing
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	t.Setenv(envConfigHome, t.TempDir())
+
+	cfg := themeConfig{
+		gtkTheme:    "Adwaita",
+		iconTheme:   "Papirus",
+		cursorTheme: "Bibata",
+		preferDark:  true,
+	}
+
+	test.RequireNoError(t, saveProfile("night", cfg))
+	test.RequireNoError(t, saveProfile("work", themeConfig{}))
+
+	record, err := loadProfile("night")
+	test.RequireNoError(t, err)
+
+	if got := record.config(); got != cfg {
+		t.Errorf("got %+v; want %+v", got, cfg)
+	}
+
+	names, err := listProfiles()
+	test.RequireNoError(t, err)
+	test.AssertStringSlicesEqual(t, names, []string{"night", "work"})
+
+	test.RequireNoError(t, deleteProfile("work"))
+
+	names, err = listProfiles()
+	test.RequireNoError(t, err)
+	test.AssertStringSlicesEqual(t, names, []string{"night"})
+
+	if _, err := loadProfile("work"); err == nil {
+		t.Error("expected error loading deleted profile")
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"", ".hidden", "../escape", "a/b"} {
+		if err := validateProfileName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestGetMissingComponents(t *testing.T) {
+	themeDirPath := setupAssetDir(t, "themes")
+
+	themePath := filepath.Join(themeDirPath, "Adwaita")
+	test.CreateEmptyDir(t, themePath)
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	got := getMissingComponents(themeConfig{
+		gtkTheme:    "Adwaita",
+		iconTheme:   "Papirus",
+		cursorTheme: "",
+	})
+
+	test.AssertStringSlicesEqual(t, got, []string{"icon theme 'Papirus'"})
+}
+
+func TestMergeProfile(t *testing.T) {
+	themeDirPath := setupAssetDir(t, "themes")
+
+	themePath := filepath.Join(themeDirPath, "Nordic")
+	test.CreateEmptyDir(t, themePath)
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	t.Setenv(envConfigHome, t.TempDir())
+
+	// saved from a backend that only reports the gtk theme
+	err := os.MkdirAll(getProfileDir(), 0o755)
+	test.RequireNoError(t, err)
+
+	err = os.WriteFile(getProfilePath("partial"), []byte(`{"gtk": "Nordic", "icon": "", "cursor": "", "color_scheme": ""}`), 0o644)
+	test.RequireNoError(t, err)
+
+	record, err := loadProfile("partial")
+	test.RequireNoError(t, err)
+
+	current := themeConfig{gtkTheme: "Adwaita", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 32}
+
+	cfg := current
+	test.RequireNoError(t, mergeProfile(&cfg, record, false))
+
+	want := themeConfig{gtkTheme: "Nordic", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 32, preferDark: true}
+	if cfg != want {
+		t.Errorf("got %+v; want %+v", cfg, want)
+	}
+
+	cfg = current
+	if err := mergeProfile(&cfg, themeRecord{Icon: "Missing"}, false); err == nil {
+		t.Error("expected error for an icon theme that is not installed")
+	}
+
+	if cfg != current {
+		t.Errorf("config changed by a rejected profile: %+v", cfg)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+var gtkVersionDir = regexp.MustCompile(`^gtk-[0-9]+\.[0-9]+$`)
+
+// themeInfo describes an installed theme based on its index.theme.
+type themeInfo struct {
+	Kind           string            `json:"kind"`
+	Path           string            `json:"path"`
+	Name           string            `json:"name"`
+	LocalizedNames map[string]string `json:"localized_names"`
+	Comment        string            `json:"comment"`
+	Inherits       []string          `json:"inherits"`
+	Directories    []string          `json:"directories"`
+	GTKVersions    []string          `json:"gtk_versions"`
+}
+
+// getThemeIndexGroup returns the group of index.theme that describes the
+// theme: [Icon Theme] for icon and cursor themes, [Desktop Entry] or
+// [X-GNOME-Metatheme] for GTK themes.
+func getThemeIndexGroup(index *desktopEntry) *desktopGroup {
+	for _, name := range []string{"Icon Theme", "Desktop Entry", "X-GNOME-Metatheme"} {
+		if g := index.group(name); g != nil {
+			return g
+		}
+	}
+
+	if len(index.groups) > 0 {
+		return index.groups[0]
+	}
+
+	return nil
+}
+
+func getThemeInfo(a asset) (themeInfo, error) {
+	index, err := readDesktopEntry(filepath.Join(a.path, "index.theme"))
+	if err != nil {
+		return themeInfo{}, fmt.Errorf("failed to read index.theme of %s: %w", a.path, err)
+	}
+
+	group := getThemeIndexGroup(index)
+
+	name, ok := group.get("Name")
+	if !ok {
+		name = a.name
+	}
+
+	comment, _ := group.get("Comment")
+
+	return themeInfo{
+		Kind:           a.kind,
+		Path:           a.path,
+		Name:           name,
+		LocalizedNames: group.getLocalized("Name"),
+		Comment:        comment,
+		Inherits:       group.getList("Inherits", ','),
+		Directories:    group.getList("Directories", ','),
+		GTKVersions:    getShippedGtkVersions(a.path),
+	}, nil
+}
+
+func getShippedGtkVersions(themePath string) []string {
+	versions := []string{}
+
+	entries, err := os.ReadDir(themePath)
+	if err != nil {
+		return versions
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && gtkVersionDir.MatchString(entry.Name()) {
+			versions = append(versions, entry.Name()[len("gtk-"):])
+		}
+	}
+
+	slices.Sort(versions)
+
+	return versions
+}
+
+// findThemesByName returns the highest precedence copy of every kind of
+// theme called name.
+func findThemesByName(name string) []asset {
+	found := []asset{}
+
+	for _, assets := range [][]asset{findInstalledThemes(), findInstalledIconThemes(), findInstalledCursorThemes()} {
+		for _, a := range assets {
+			if a.name == name && !a.shadowed {
+				found = append(found, a)
+				break
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func TestGetThemeInfo(t *testing.T) {
+	themeDirPath := setupAssetDir(t, "themes")
+
+	themePath := filepath.Join(themeDirPath, "Nordic")
+	test.CreateEmptyDir(t, filepath.Join(themePath, "gtk-2.0"))
+	test.CreateEmptyDir(t, filepath.Join(themePath, "gtk-3.0"))
+	test.CreateEmptyDir(t, filepath.Join(themePath, "gtk-4.0"))
+	test.CreateEmptyDir(t, filepath.Join(themePath, "gnome-shell"))
+	test.CreateEmptyFile(t, filepath.Join(themePath, "index.theme"))
+
+	found := findThemesByName("Nordic")
+	if len(found) != 1 {
+		t.Fatalf("got %d themes; want 1", len(found))
+	}
+
+	got, err := getThemeInfo(found[0])
+	test.RequireNoError(t, err)
+
+	if got.Name != "Nordic" || got.Path != themePath || got.Kind != assetGtk {
+		t.Errorf("got %+v", got)
+	}
+
+	test.AssertStringSlicesEqual(t, got.GTKVersions, []string{"2.0", "3.0", "4.0"})
+}
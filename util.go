@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 )
@@ -16,143 +17,192 @@ const (
 	envXdgDataHome = "XDG_DATA_HOME"
 	envXdgDataDirs = "XDG_DATA_DIRS"
 	envConfigHome  = "XDG_CONFIG_HOME"
+	envStateHome   = "XDG_STATE_HOME"
 	envHome        = "HOME"
 )
 
-func saveCurrentTheme(cfg themeConfig) error {
-	if err := saveConfigToFile(cfg); err != nil {
-		return err
-	}
+func saveCurrentTheme(b backend, cfg themeConfig) error {
+	steps := configFileSteps(cfg)
+	steps = append(steps, &backendStep{b: b, cfg: cfg})
+	steps = append(steps, compositorSteps(b, cfg, runCommand)...)
 
-	if err := saveConfigWithGsettings(cfg); err != nil {
+	if err := runTransaction(steps); err != nil {
 		return err
 	}
 
+	warnSnippetsNotIncluded(steps)
+
 	return nil
 }
 
-func saveConfigToFile(cfg themeConfig) error {
-	settingsIniContent := fmt.Sprintf(
-		`[Settings]
-gtk-theme-name=%s
-gtk-icon-theme-name=%s
-gtk-cursor-theme-name=%s
-gtk-application-prefer-dark-theme=%t`,
-		cfg.gtkTheme,
-		cfg.iconTheme,
-		cfg.cursorTheme,
-		cfg.preferDark,
-	)
-
-	gtkrc2Content := fmt.Sprintf(
-		`gtk-theme-name="%s"
-gtk-icon-theme-name="%s"
-gtk-cursor-theme-name="%s"`,
-		cfg.gtkTheme,
-		cfg.iconTheme,
-		cfg.cursorTheme,
-	)
-
+func configFileSteps(cfg themeConfig) []applyStep {
 	configHome := getConfigDir()
 
-	gtk2File := filepath.Join(os.Getenv(envHome), ".gtkrc-2.0")
-	gtk3Dir := filepath.Join(configHome, "gtk-3.0")
-	gtk4Dir := filepath.Join(configHome, "gtk-4.0")
-
-	if err := os.MkdirAll(gtk3Dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create gtk-3.0 directory: %w", err)
+	renderSettings := func(old []byte) []byte {
+		return renderSettingsIni(old, cfg)
 	}
 
-	if err := os.MkdirAll(gtk4Dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create gtk-4.0 directory: %w", err)
+	renderGtk2 := func(old []byte) []byte {
+		return renderGtkrc(old, cfg)
 	}
 
-	if err := os.WriteFile(filepath.Join(gtk3Dir, "settings.ini"), []byte(settingsIniContent), 0o644); err != nil {
-		return fmt.Errorf("failed to write to gtk-3.0/settings.ini: %w", err)
-	}
+	renderXresources := func(old []byte) []byte {
+		xres := parseXresources(old)
 
-	if err := os.WriteFile(filepath.Join(gtk4Dir, "settings.ini"), []byte(settingsIniContent), 0o644); err != nil {
-		return fmt.Errorf("failed to write to gtk-4.0/settings.ini: %w", err)
-	}
+		if cfg.cursorTheme != "" {
+			xres.set("Xcursor.theme", cfg.cursorTheme)
+		}
+
+		if cfg.cursorSize > 0 {
+			xres.set("Xcursor.size", strconv.Itoa(cfg.cursorSize))
+		}
 
-	if err := os.WriteFile(gtk2File, []byte(gtkrc2Content), 0o644); err != nil {
-		return fmt.Errorf("failed to write to .gtkrc-2.0: %w", err)
+		return xres.bytes()
 	}
 
-	return nil
-}
+	renderEnvironment := func(old []byte) []byte {
+		env := parseINI(old)
 
-func saveConfigWithGsettings(cfg themeConfig) error {
-	colorScheme := "prefer-light"
+		if cfg.cursorSize > 0 {
+			env.set("", "XCURSOR_SIZE", strconv.Itoa(cfg.cursorSize))
+		}
 
-	if cfg.preferDark {
-		colorScheme = "prefer-dark"
+		return env.bytes()
 	}
 
-	if err := setGsettingsValue(gnomeDesktopInterface, "gtk-theme", cfg.gtkTheme); err != nil {
-		return fmt.Errorf("failed to set gtk theme: %w", err)
+	renderDefaultCursor := func(old []byte) []byte {
+		return renderDefaultCursorTheme(old, cfg)
 	}
 
-	if err := setGsettingsValue(gnomeDesktopInterface, "icon-theme", cfg.iconTheme); err != nil {
-		return fmt.Errorf("failed to set icon theme: %w", err)
-	}
+	gtk2File := getGtkrc2Path()
 
-	if err := setGsettingsValue(gnomeDesktopInterface, "cursor-theme", cfg.cursorTheme); err != nil {
-		return fmt.Errorf("failed to set cursor theme: %w", err)
+	steps := []applyStep{
+		newFileStep("gtk-3.0/settings.ini", filepath.Join(configHome, "gtk-3.0", "settings.ini"), renderSettings),
+		newFileStep("gtk-4.0/settings.ini", filepath.Join(configHome, "gtk-4.0", "settings.ini"), renderSettings),
+		newFileStep(filepath.Base(gtk2File), gtk2File, renderGtk2),
+		newFileStep(".Xresources", getXresourcesPath(), renderXresources),
+		newFileStep("environment.d/lookctl.conf", getEnvironmentFilePath(), renderEnvironment),
 	}
 
-	if err := setGsettingsValue(gnomeDesktopInterface, "color-scheme", colorScheme); err != nil {
-		return fmt.Errorf("failed to set color scheme: %w", err)
+	// A symlinked default theme usually points at an installed theme, which
+	// must not be edited in place.
+	defaultCursorDir := filepath.Dir(getDefaultCursorThemePath())
+	if fi, err := os.Lstat(defaultCursorDir); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if cfg.cursorTheme != "" && cfg.cursorTheme != getInheritedCursorTheme(defaultCursorDir) {
+			fmt.Fprintf(os.Stderr, "warning: %s is a symlink, not updating the default cursor theme\n", defaultCursorDir)
+		}
+
+		return steps
 	}
 
-	return nil
+	return append(steps, newFileStep(".icons/default/index.theme", getDefaultCursorThemePath(), renderDefaultCursor))
 }
 
-func setGsettingsValue(schema, key, value string) error {
-	cmd := exec.Command("gsettings", "set", schema, key, value)
+// getDefaultCursorThemePath returns the index.theme of the "default" icon
+// theme, which X11 clients that ignore XSETTINGS use to find the cursor.
+func getDefaultCursorThemePath() string {
+	return filepath.Join(os.Getenv(envHome), ".icons", "default", "index.theme")
+}
 
-	if err := cmd.Run(); err != nil {
-		return err
+// getInheritedCursorTheme returns the cursor theme a default theme
+// directory resolves to: the theme it inherits from, or for a symlink to
+// a cursor theme, the theme it points at.
+func getInheritedCursorTheme(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "index.theme")); err == nil {
+		if value, ok := parseINI(data).get("Icon Theme", "Inherits"); ok && value != "" {
+			first, _, _ := strings.Cut(value, ",")
+
+			return strings.TrimSpace(first)
+		}
 	}
 
-	return nil
+	target, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Base(target)
 }
 
-func getGsettingsValue(schema, key string) (string, error) {
-	cmd := exec.Command("gsettings", "get", schema, key)
+// renderDefaultCursorTheme points the default theme at the cursor theme
+// through Inherits, keeping any other keys already in the file.
+func renderDefaultCursorTheme(old []byte, cfg themeConfig) []byte {
+	ini := parseINI(old)
 
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", err
+	if cfg.cursorTheme == "" {
+		return ini.bytes()
 	}
 
-	outStr := strings.TrimSpace(string(out))
+	if _, ok := ini.get("Icon Theme", "Name"); !ok {
+		ini.set("Icon Theme", "Name", "Default")
+	}
+
+	ini.set("Icon Theme", "Inherits", cfg.cursorTheme)
+
+	return ini.bytes()
+}
+
+// getEnvironmentFilePath returns the systemd environment.d file lookctl
+// uses to export variables such as XCURSOR_SIZE to the user session.
+func getEnvironmentFilePath() string {
+	return filepath.Join(getConfigDir(), "environment.d", "lookctl.conf")
+}
+
+// renderSettingsIni updates the keys owned by lookctl in a GTK 3/4
+// settings.ini, leaving everything else in the file untouched.
+func renderSettingsIni(old []byte, cfg themeConfig) []byte {
+	ini := parseINI(old)
+
+	ini.set("Settings", "gtk-theme-name", cfg.gtkTheme)
+	ini.set("Settings", "gtk-icon-theme-name", cfg.iconTheme)
+	ini.set("Settings", "gtk-cursor-theme-name", cfg.cursorTheme)
 
-	outStr = strings.Trim(outStr, "'")
+	if cfg.cursorSize > 0 {
+		ini.set("Settings", "gtk-cursor-theme-size", strconv.Itoa(cfg.cursorSize))
+	}
+
+	ini.set("Settings", "gtk-application-prefer-dark-theme", strconv.FormatBool(cfg.preferDark))
 
-	return outStr, nil
+	return ini.bytes()
 }
 
+// getAssetSearchPaths returns existing asset directories in precedence
+// order: the user data dir, the legacy dir in home, then XDG_DATA_DIRS.
 func getAssetSearchPaths(subDir, legacyDir string) []string {
-	assetPaths := []string{}
+	candidates := []string{}
 
-	dataDirs := getDataDirs()
+	if dataHome := getDataHomeDir(); dataHome != "" {
+		candidates = append(candidates, filepath.Join(dataHome, subDir))
+	}
 
-	for _, dir := range dataDirs {
-		if isDir(filepath.Join(dir, subDir)) {
-			assetPaths = append(assetPaths, filepath.Join(dir, subDir))
-		}
+	if home := os.Getenv(envHome); home != "" {
+		candidates = append(candidates, filepath.Join(home, legacyDir))
 	}
 
-	if isDir(filepath.Join(os.Getenv(envHome), legacyDir)) {
-		assetPaths = append(assetPaths, filepath.Join(os.Getenv(envHome), legacyDir))
+	for _, dir := range getSystemDataDirs() {
+		candidates = append(candidates, filepath.Join(dir, subDir))
+	}
+
+	assetPaths := []string{}
+
+	for _, path := range candidates {
+		if isDir(path) && !slices.Contains(assetPaths, path) {
+			assetPaths = append(assetPaths, path)
+		}
 	}
 
 	return assetPaths
 }
 
 func getAssets(searchPaths []string, isValidAsset func(fullpath, entryName string) bool) []string {
-	assetList := []string{}
+	return getAssetNames(findAssets(searchPaths, "", isValidAsset))
+}
+
+// findAssets returns every valid asset in searchPaths. An asset is marked
+// as shadowed when one with the same name was found in an earlier path.
+func findAssets(searchPaths []string, kind string, isValidAsset func(fullpath, entryName string) bool) []asset {
+	assetList := []asset{}
+	seen := map[string]bool{}
 
 	for _, dir := range searchPaths {
 		entries, err := os.ReadDir(dir)
@@ -169,7 +219,14 @@ func getAssets(searchPaths []string, isValidAsset func(fullpath, entryName strin
 			fullPath := filepath.Join(dir, entry.Name())
 
 			if isValidAsset(fullPath, entry.Name()) {
-				assetList = append(assetList, entry.Name())
+				assetList = append(assetList, asset{
+					name:     entry.Name(),
+					path:     fullPath,
+					kind:     kind,
+					shadowed: seen[entry.Name()],
+				})
+
+				seen[entry.Name()] = true
 			}
 		}
 	}
@@ -177,21 +234,69 @@ func getAssets(searchPaths []string, isValidAsset func(fullpath, entryName strin
 	return assetList
 }
 
+// getAssetNames returns the names of all assets that are not shadowed.
+func getAssetNames(assets []asset) []string {
+	names := make([]string, 0, len(assets))
+
+	for _, a := range assets {
+		if !a.shadowed {
+			names = append(names, a.name)
+		}
+	}
+
+	return names
+}
+
+// sortAssets sorts by name, keeping search path order between assets that
+// share a name.
+func sortAssets(assets []asset) {
+	slices.SortStableFunc(assets, func(a, b asset) int {
+		return strings.Compare(a.name, b.name)
+	})
+}
+
+// getDataDirs returns the XDG data dirs in precedence order, starting with
+// the user data dir.
 func getDataDirs() []string {
-	systemDataDirs := os.Getenv(envXdgDataDirs)
-	if systemDataDirs == "" {
-		systemDataDirs = "/usr/local/share:/usr/share"
+	dataDirs := []string{}
+
+	if dataHome := getDataHomeDir(); dataHome != "" {
+		dataDirs = append(dataDirs, dataHome)
+	}
+
+	for _, dir := range getSystemDataDirs() {
+		if !slices.Contains(dataDirs, dir) {
+			dataDirs = append(dataDirs, dir)
+		}
 	}
 
+	return dataDirs
+}
+
+func getDataHomeDir() string {
 	homeDataDir := os.Getenv(envXdgDataHome)
-	if homeDataDir == "" {
+	if homeDataDir == "" && os.Getenv(envHome) != "" {
 		homeDataDir = filepath.Join(os.Getenv(envHome), ".local", "share")
 	}
 
-	dataDirs := strings.Split(systemDataDirs, ":")
-	dataDirs = append(dataDirs, homeDataDir)
+	return homeDataDir
+}
 
-	return dataDirs
+func getSystemDataDirs() []string {
+	systemDataDirs := os.Getenv(envXdgDataDirs)
+	if systemDataDirs == "" {
+		systemDataDirs = "/usr/local/share:/usr/share"
+	}
+
+	dirs := []string{}
+
+	for _, dir := range strings.Split(systemDataDirs, ":") {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
 }
 
 func getConfigDir() string {
@@ -203,6 +308,32 @@ func getConfigDir() string {
 	return configHome
 }
 
+func getStateDir() string {
+	stateHome := os.Getenv(envStateHome)
+	if stateHome == "" {
+		stateHome = filepath.Join(os.Getenv(envHome), ".local", "state")
+	}
+
+	return stateHome
+}
+
+// getLookctlConfigPath returns lookctl's own settings file, an INI file
+// with a section per backend.
+func getLookctlConfigPath() string {
+	return filepath.Join(getConfigDir(), "lookctl", "lookctl.conf")
+}
+
+// readLookctlConfig returns lookctl's settings, which are empty when the
+// file does not exist.
+func readLookctlConfig() (*iniFile, error) {
+	data, err := os.ReadFile(getLookctlConfigPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", getLookctlConfigPath(), err)
+	}
+
+	return parseINI(data), nil
+}
+
 func isDir(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -260,6 +391,25 @@ func parseFlag(fs *flag.FlagSet, args []string, printHelp func(*tabwriter.Writer
 	return nil
 }
 
+// parseInterspersed parses flags that appear before, between, or after
+// positional arguments and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string, printHelp func(*tabwriter.Writer)) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := parseFlag(fs, args, printHelp); err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
 func newTabWriter(w io.Writer) *tabwriter.Writer {
 	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
 
@@ -267,12 +417,26 @@ func newTabWriter(w io.Writer) *tabwriter.Writer {
 }
 
 func printMainHelp(w *tabwriter.Writer) {
-	fmt.Fprintln(w, "Usage: lookctl <command> [arguments]")
+	fmt.Fprintln(w, "Usage: lookctl [options] <command> [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-backend, --backend\tForce a settings backend, or several separated by commas (detected from XDG_CURRENT_DESKTOP by default)")
+	fmt.Fprintln(w, "\t-format, --format\tOutput format of 'current', 'cursor', 'icon', 'info', and 'list': text, json, or yaml (default: text)")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "\tbackends\tShow available settings backends")
+	fmt.Fprintln(w, "\tcheck\tValidate the inheritance chain of icon themes")
 	fmt.Fprintln(w, "\tcurrent\tShow the currently used theme, icon, and cursor")
+	fmt.Fprintln(w, "\tcursor\tInspect cursor themes")
+	fmt.Fprintln(w, "\thistory\tShow previously applied changes")
+	fmt.Fprintln(w, "\ticon\tInspect icon themes")
+	fmt.Fprintln(w, "\tinfo\tShow details of an installed theme")
 	fmt.Fprintln(w, "\tlist\tShow installed themes")
+	fmt.Fprintln(w, "\tprofile\tSave and apply named looks")
+	fmt.Fprintln(w, "\tredo\tReapply the last undone change")
+	fmt.Fprintln(w, "\trestore-backup\tRestore config files to their state before lookctl first changed them")
 	fmt.Fprintln(w, "\tset\tSet the theme, icon, or cursor")
+	fmt.Fprintln(w, "\tundo\tRevert the last applied change")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Run 'lookctl <command> -h' for more information on a command.")
 
@@ -283,9 +447,11 @@ func printListHelp(w *tabwriter.Writer) {
 	fmt.Fprintln(w, "Usage: lookctl list [options]")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-all-locations, --all-locations\tShow every installed copy, including ones shadowed by a higher precedence copy")
 	fmt.Fprintln(w, "\t-cursor, --cursor\tShow installed cursor themes")
 	fmt.Fprintln(w, "\t-gtk, --gtk\tShow installed themes (selected by default)")
 	fmt.Fprintln(w, "\t-icon, --icon\tShow installed icon themes")
+	fmt.Fprintln(w, "\t-template, --template\tFormat each theme with a Go template, e.g. '{{.Kind}}: {{.Name}} ({{.Path}})'")
 
 	w.Flush()
 }
@@ -294,8 +460,10 @@ func printSetHelp(w *tabwriter.Writer) {
 	fmt.Fprintln(w, "Usage: lookctl set [options] [arguments]")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Options:")
-	fmt.Fprintln(w, "\t-color-scheme, --color-scheme\tManually set color theme")
+	fmt.Fprintln(w, "\t-color-scheme, --color-scheme\tManually set color theme; on KDE the scheme is taken from dark-color-scheme or light-color-scheme in the [kde] section of ~/.config/lookctl/lookctl.conf")
 	fmt.Fprintln(w, "\t-cursor, --cursor\tSet cursor theme")
+	fmt.Fprintln(w, "\t-cursor-size, --cursor-size\tSet cursor size; must be a size shipped by the cursor theme")
+	fmt.Fprintln(w, "\t-force, --force\tApply an icon theme even if its inheritance chain is broken")
 	fmt.Fprintln(w, "\t-gtk, --gtk\tSet theme")
 	fmt.Fprintln(w, "\t-icon, --icon\tSet icon theme")
 
@@ -303,8 +471,165 @@ func printSetHelp(w *tabwriter.Writer) {
 }
 
 func printCurrentHelp(w *tabwriter.Writer) {
-	fmt.Fprintln(w, "Usage: lookctl current")
+	fmt.Fprintln(w, "Usage: lookctl current [options]")
 	fmt.Fprintln(w, "Show applied themes")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-template, --template\tFormat the output with a Go template, e.g. '{{.GTK}} / {{.Icon}}'")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Template fields:")
+	fmt.Fprintln(w, "\t.Backend, .GTK, .Icon, .Cursor, .ColorScheme, .GTKPath, .IconPath, .CursorPath")
+
+	w.Flush()
+}
+
+func printBackendsHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl backends")
+	fmt.Fprintln(w, "Show settings backends, their capabilities, and which one is selected")
+
+	w.Flush()
+}
+
+func printRestoreBackupHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl restore-backup [options] [files]")
+	fmt.Fprintln(w, "Restore config files from the backups taken before lookctl first modified them.")
+	fmt.Fprintln(w, "All backed up files are restored when no files are given.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-list, --list\tShow available backups instead of restoring")
+
+	w.Flush()
+}
+
+func printHistoryHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl history")
+	fmt.Fprintln(w, "Show previously applied changes, oldest first")
+
+	w.Flush()
+}
+
+func printUndoHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl undo")
+	fmt.Fprintln(w, "Revert the last applied change")
+
+	w.Flush()
+}
+
+func printRedoHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl redo")
+	fmt.Fprintln(w, "Reapply the last change reverted by 'lookctl undo'")
+
+	w.Flush()
+}
+
+func printProfileHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl profile [options] <command> [name]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-force, --force\tApply an icon theme even if its inheritance chain is broken")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "\tapply <name>\tApply the components a saved profile sets, keeping the rest")
+	fmt.Fprintln(w, "\tdelete <name>\tDelete a saved profile")
+	fmt.Fprintln(w, "\tlist\tShow saved profiles")
+	fmt.Fprintln(w, "\tsave <name>\tSave the current look as a profile")
+	fmt.Fprintln(w, "\tshow <name>\tShow the contents of a profile")
+
+	w.Flush()
+}
+
+func printInfoHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl info <name>")
+	fmt.Fprintln(w, "Show the index.theme metadata, location, and supported GTK versions of a theme")
+
+	w.Flush()
+}
+
+func printIconHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl icon <command> [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "\tcache\tBuild or verify the GTK icon cache of a theme")
+	fmt.Fprintln(w, "\tcoverage\tReport how many application icons a theme provides")
+	fmt.Fprintln(w, "\tlookup\tResolve an icon name to a file")
+
+	w.Flush()
+}
+
+func printIconCacheHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl icon cache <build|verify> <theme>")
+	fmt.Fprintln(w, "Write icon-theme.cache in the highest precedence directory of a theme, or check that the existing cache is valid and newer than the theme directories")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "\tbuild\tGenerate the cache, like gtk-update-icon-cache")
+	fmt.Fprintln(w, "\tverify\tReport a missing, invalid, or outdated cache")
+
+	w.Flush()
+}
+
+func printIconLookupHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl icon lookup <icon-name> [options]")
+	fmt.Fprintln(w, "Resolve an icon through the theme, its parents, and hicolor, and print the chosen file")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-scale, --scale\tIcon scale (default: 1)")
+	fmt.Fprintln(w, "\t-size, --size\tIcon size in pixels (default: 48)")
+	fmt.Fprintln(w, "\t-theme, --theme\tIcon theme to search (default: current icon theme)")
+
+	w.Flush()
+}
+
+func printIconCoverageHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl icon coverage [theme]")
+	fmt.Fprintln(w, "Check the icons of every installed application against an icon theme (default: current icon theme).")
+	fmt.Fprintln(w, "Icons are native when the theme or its parents provide them, fallback when only hicolor or pixmaps do.")
+
+	w.Flush()
+}
+
+func printCheckHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl check [icon-themes]")
+	fmt.Fprintln(w, "Check icon themes (default: current icon theme) for missing parents and inheritance cycles")
+
+	w.Flush()
+}
+
+func printCursorHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl cursor <command> [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "\tconvert\tConvert an Xcursor theme to another cursor format")
+	fmt.Fprintln(w, "\tinfo\tShow sizes, animation frames, and standard cursor coverage of a theme")
+	fmt.Fprintln(w, "\tpreview\tRender the standard cursors of a theme to a PNG contact sheet")
+
+	w.Flush()
+}
+
+func printCursorConvertHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl cursor convert [options] <theme>")
+	fmt.Fprintln(w, "Convert an Xcursor theme, keeping hotspots, sizes, animation delays, and symlinked aliases")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-to, --to\tTarget format: hyprcursor (required)")
+	fmt.Fprintln(w, "\t-out, --out\tOutput theme directory (required)")
+
+	w.Flush()
+}
+
+func printCursorInfoHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl cursor info <theme>")
+	fmt.Fprintln(w, "Parse the Xcursor files of a cursor theme and report sizes, frames, and missing or dangling cursors")
+
+	w.Flush()
+}
+
+func printCursorPreviewHelp(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "Usage: lookctl cursor preview [options] <theme>")
+	fmt.Fprintln(w, "Render the standard cursors of a theme to a labelled PNG sheet, using the closest available size")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "\t-o\tOutput PNG file (required)")
+	fmt.Fprintln(w, "\t-size, --size\tCursor size to render (default: 24)")
 
 	w.Flush()
 }
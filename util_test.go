@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -32,6 +33,13 @@ func TestGetDataDirs(t *testing.T) {
 			xdgDataHome: "",
 			want:        []string{"/usr/share", "/usr/local/share", "/home/test/.local/share"},
 		},
+		{
+			description: "skips empty and duplicate entries",
+			home:        "/home/test",
+			xdgDataDir:  "/usr/share::/usr/share:",
+			xdgDataHome: "",
+			want:        []string{"/usr/share", "/home/test/.local/share"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -81,6 +89,64 @@ func TestGetAssertSearchPaths(t *testing.T) {
 	test.AssertStringSlicesEqual(t, got, want)
 }
 
+func TestGetAssetSearchPathsPrecedence(t *testing.T) {
+	tempDir := t.TempDir()
+
+	homePath := filepath.Join(tempDir, "home")
+	dataHomePath := filepath.Join(homePath, ".local", "share")
+	dataDirPath1 := filepath.Join(tempDir, "usr", "local", "share")
+	dataDirPath2 := filepath.Join(tempDir, "usr", "share")
+
+	t.Setenv(envHome, homePath)
+	t.Setenv(envXdgDataHome, "")
+	t.Setenv(envXdgDataDirs, fmt.Sprintf("%s:%s:%s", dataDirPath1, dataDirPath2, dataDirPath1))
+
+	want := []string{
+		filepath.Join(dataHomePath, "icons"),
+		filepath.Join(homePath, ".icons"),
+		filepath.Join(dataDirPath1, "icons"),
+		filepath.Join(dataDirPath2, "icons"),
+	}
+
+	for _, path := range want {
+		test.CreateEmptyDir(t, path)
+	}
+
+	got := getAssetSearchPaths("icons", ".icons")
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestFindAssetsShadowing(t *testing.T) {
+	tempDir := t.TempDir()
+
+	userPath := filepath.Join(tempDir, "user")
+	systemPath := filepath.Join(tempDir, "system")
+
+	test.CreateEmptyDir(t, filepath.Join(userPath, "Adwaita"))
+	test.CreateEmptyDir(t, filepath.Join(systemPath, "Adwaita"))
+	test.CreateEmptyDir(t, filepath.Join(systemPath, "Breeze"))
+
+	got := findAssets([]string{userPath, systemPath}, assetGtk, func(fullpath, entryName string) bool {
+		return true
+	})
+	sortAssets(got)
+
+	want := []asset{
+		{name: "Adwaita", path: filepath.Join(userPath, "Adwaita"), kind: assetGtk},
+		{name: "Adwaita", path: filepath.Join(systemPath, "Adwaita"), kind: assetGtk, shadowed: true},
+		{name: "Breeze", path: filepath.Join(systemPath, "Breeze"), kind: assetGtk},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+
+	test.AssertStringSlicesEqual(t, getAssetNames(got), []string{"Adwaita", "Breeze"})
+}
+
 func TestGetAssets(t *testing.T) {
 	// supress warning output
 	ogStderr := os.Stderr
@@ -117,3 +183,133 @@ func TestGetAssets(t *testing.T) {
 
 	test.AssertStringSlicesEqual(t, got, want)
 }
+
+// setupSaveHome points every path saveCurrentTheme writes to into a
+// temporary home directory.
+func setupSaveHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+
+	t.Setenv(envHome, home)
+	t.Setenv(envConfigHome, filepath.Join(home, ".config"))
+	t.Setenv(envStateHome, filepath.Join(home, ".local", "state"))
+	t.Setenv(envGtk2RcFiles, "")
+	t.Setenv(envHyprlandInstance, "")
+	t.Setenv(envSwaySock, "")
+
+	return home
+}
+
+func TestSaveCurrentThemeCursorSize(t *testing.T) {
+	home := setupSaveHome(t)
+
+	cfg := themeConfig{gtkTheme: "Arc", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 48}
+	test.RequireNoError(t, saveCurrentTheme(&fakeBackend{}, cfg))
+
+	readFile := func(path string) []byte {
+		t.Helper()
+
+		data, err := os.ReadFile(path)
+		test.RequireNoError(t, err)
+
+		return data
+	}
+
+	for _, version := range []string{"gtk-3.0", "gtk-4.0"} {
+		settings := readFile(filepath.Join(home, ".config", version, "settings.ini"))
+
+		if value, _ := parseINI(settings).get("Settings", "gtk-cursor-theme-size"); value != "48" {
+			t.Errorf("got %s gtk-cursor-theme-size %q; want %q", version, value, "48")
+		}
+	}
+
+	if value, _ := parseGtkrc(readFile(filepath.Join(home, ".gtkrc-2.0"))).get("gtk-cursor-theme-size"); value != "48" {
+		t.Errorf("got gtkrc-2.0 gtk-cursor-theme-size %q; want %q", value, "48")
+	}
+
+	if value, _ := parseXresources(readFile(filepath.Join(home, ".Xresources"))).get("Xcursor.size"); value != "48" {
+		t.Errorf("got Xcursor.size %q; want %q", value, "48")
+	}
+
+	if value, _ := parseINI(readFile(filepath.Join(home, ".config", "environment.d", "lookctl.conf"))).get("", "XCURSOR_SIZE"); value != "48" {
+		t.Errorf("got XCURSOR_SIZE %q; want %q", value, "48")
+	}
+}
+
+func TestRenderDefaultCursorTheme(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		want        string
+	}{
+		{
+			description: "new file",
+			input:       "",
+			want:        "[Icon Theme]\nName=Default\nInherits=Bibata\n",
+		},
+		{
+			description: "existing file",
+			input:       "[Icon Theme]\nName=My Default\nComment=custom\nInherits=Adwaita\n",
+			want:        "[Icon Theme]\nName=My Default\nComment=custom\nInherits=Bibata\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got := string(renderDefaultCursorTheme([]byte(tt.input), themeConfig{cursorTheme: "Bibata"}))
+
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveCurrentThemeDefaultCursor(t *testing.T) {
+	home := setupSaveHome(t)
+
+	cfg := themeConfig{gtkTheme: "Arc", iconTheme: "Papirus", cursorTheme: "Bibata"}
+	test.RequireNoError(t, saveCurrentTheme(&fakeBackend{}, cfg))
+
+	data, err := os.ReadFile(filepath.Join(home, ".icons", "default", "index.theme"))
+	test.RequireNoError(t, err)
+
+	if value, _ := parseINI(data).get("Icon Theme", "Inherits"); value != "Bibata" {
+		t.Errorf("got Inherits %q; want %q", value, "Bibata")
+	}
+
+	data, err = os.ReadFile(filepath.Join(home, ".Xresources"))
+	test.RequireNoError(t, err)
+
+	if value, _ := parseXresources(data).get("Xcursor.theme"); value != "Bibata" {
+		t.Errorf("got Xcursor.theme %q; want %q", value, "Bibata")
+	}
+}
+
+func TestSaveCurrentThemeDefaultCursorSymlink(t *testing.T) {
+	home := setupSaveHome(t)
+
+	installed := filepath.Join(home, "installed", "Adwaita")
+	test.CreateEmptyDir(t, installed)
+
+	original := "[Icon Theme]\nName=Adwaita\n"
+	test.RequireNoError(t, os.WriteFile(filepath.Join(installed, "index.theme"), []byte(original), 0o644))
+
+	test.CreateEmptyDir(t, filepath.Join(home, ".icons"))
+	test.RequireNoError(t, os.Symlink(installed, filepath.Join(home, ".icons", "default")))
+
+	if got := getInheritedCursorTheme(filepath.Join(home, ".icons", "default")); got != "Adwaita" {
+		t.Errorf("got inherited cursor theme %q; want %q", got, "Adwaita")
+	}
+
+	cfg := themeConfig{gtkTheme: "Arc", iconTheme: "Papirus", cursorTheme: "Bibata"}
+	test.RequireNoError(t, saveCurrentTheme(&fakeBackend{}, cfg))
+
+	data, err := os.ReadFile(filepath.Join(installed, "index.theme"))
+	test.RequireNoError(t, err)
+
+	if string(data) != original {
+		t.Errorf("theme behind the symlink was edited:\n%s", data)
+	}
+}
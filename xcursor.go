@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+const (
+	xcursorMagic        = "Xcur"
+	xcursorFileHeader   = 16
+	xcursorTocEntry     = 12
+	xcursorImageHeader  = 36
+	xcursorImageType    = 0xfffd0002
+	xcursorMaxImageSize = 0x7fff
+)
+
+// xcursorImage is a single frame of an Xcursor file. pixels holds
+// premultiplied ARGB values in row major order.
+type xcursorImage struct {
+	size   int
+	width  int
+	height int
+	xhot   int
+	yhot   int
+	delay  int
+	pixels []uint32
+}
+
+type xcursorFile struct {
+	images []xcursorImage
+}
+
+func readXcursor(path string) (*xcursorFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXcursor(data)
+}
+
+// parseXcursor decodes the image chunks of an Xcursor file. Other chunk
+// types, such as comments, are skipped.
+func parseXcursor(data []byte) (*xcursorFile, error) {
+	if len(data) < xcursorFileHeader || string(data[:4]) != xcursorMagic {
+		return nil, errors.New("not an Xcursor file")
+	}
+
+	le := binary.LittleEndian
+
+	headerSize := le.Uint32(data[4:])
+	ntoc := le.Uint32(data[12:])
+
+	if uint64(headerSize)+uint64(ntoc)*xcursorTocEntry > uint64(len(data)) {
+		return nil, errors.New("truncated table of contents")
+	}
+
+	file := &xcursorFile{}
+
+	for i := range ntoc {
+		entry := data[headerSize+i*xcursorTocEntry:]
+
+		chunkType := le.Uint32(entry)
+		position := le.Uint32(entry[8:])
+
+		if chunkType != xcursorImageType {
+			continue
+		}
+
+		img, err := parseXcursorImage(data, position)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+
+		file.images = append(file.images, img)
+	}
+
+	if len(file.images) == 0 {
+		return nil, errors.New("no images")
+	}
+
+	return file, nil
+}
+
+func parseXcursorImage(data []byte, position uint32) (xcursorImage, error) {
+	le := binary.LittleEndian
+
+	if uint64(position)+xcursorImageHeader > uint64(len(data)) {
+		return xcursorImage{}, errors.New("truncated image header")
+	}
+
+	chunk := data[position:]
+
+	if le.Uint32(chunk[4:]) != xcursorImageType {
+		return xcursorImage{}, errors.New("chunk type does not match table of contents")
+	}
+
+	img := xcursorImage{
+		size:   int(le.Uint32(chunk[8:])),
+		width:  int(le.Uint32(chunk[16:])),
+		height: int(le.Uint32(chunk[20:])),
+		xhot:   int(le.Uint32(chunk[24:])),
+		yhot:   int(le.Uint32(chunk[28:])),
+		delay:  int(le.Uint32(chunk[32:])),
+	}
+
+	if img.width <= 0 || img.height <= 0 || img.width > xcursorMaxImageSize || img.height > xcursorMaxImageSize {
+		return xcursorImage{}, fmt.Errorf("invalid dimensions %dx%d", img.width, img.height)
+	}
+
+	if img.xhot > img.width || img.yhot > img.height {
+		return xcursorImage{}, errors.New("hotspot outside of image")
+	}
+
+	headerSize := int(le.Uint32(chunk))
+	pixelCount := img.width * img.height
+
+	if headerSize < xcursorImageHeader || headerSize+pixelCount*4 > len(chunk) {
+		return xcursorImage{}, errors.New("truncated pixel data")
+	}
+
+	img.pixels = make([]uint32, pixelCount)
+	for i := range img.pixels {
+		img.pixels[i] = le.Uint32(chunk[headerSize+i*4:])
+	}
+
+	return img, nil
+}
+
+// sizes returns the distinct nominal sizes in the file in ascending order.
+func (f *xcursorFile) sizes() []int {
+	sizes := []int{}
+
+	for _, img := range f.images {
+		if !slices.Contains(sizes, img.size) {
+			sizes = append(sizes, img.size)
+		}
+	}
+
+	slices.Sort(sizes)
+
+	return sizes
+}
+
+// frames returns the animation frames of the given nominal size.
+func (f *xcursorFile) frames(size int) []xcursorImage {
+	frames := []xcursorImage{}
+
+	for _, img := range f.images {
+		if img.size == size {
+			frames = append(frames, img)
+		}
+	}
+
+	return frames
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+func encodeTestXcursor(images []xcursorImage) []byte {
+	le := binary.LittleEndian
+
+	data := []byte(xcursorMagic)
+	data = le.AppendUint32(data, xcursorFileHeader)
+	data = le.AppendUint32(data, 0x10000)
+	data = le.AppendUint32(data, uint32(len(images)+1))
+
+	position := xcursorFileHeader + (len(images)+1)*xcursorTocEntry
+
+	// a comment chunk that the parser has to skip
+	data = le.AppendUint32(data, 0xfffe0001)
+	data = le.AppendUint32(data, 1)
+	data = le.AppendUint32(data, 0)
+
+	for _, img := range images {
+		data = le.AppendUint32(data, xcursorImageType)
+		data = le.AppendUint32(data, uint32(img.size))
+		data = le.AppendUint32(data, uint32(position))
+
+		position += xcursorImageHeader + len(img.pixels)*4
+	}
+
+	for _, img := range images {
+		for _, v := range []int{xcursorImageHeader, xcursorImageType, img.size, 1, img.width, img.height, img.xhot, img.yhot, img.delay} {
+			data = le.AppendUint32(data, uint32(v))
+		}
+
+		for _, pixel := range img.pixels {
+			data = le.AppendUint32(data, pixel)
+		}
+	}
+
+	return data
+}
+
+func newTestXcursorImage(size, delay int) xcursorImage {
+	pixels := make([]uint32, size*size)
+	for i := range pixels {
+		pixels[i] = 0xff000000 | uint32(i)
+	}
+
+	return xcursorImage{size: size, width: size, height: size, xhot: 1, yhot: 2, delay: delay, pixels: pixels}
+}
+
+func TestParseXcursor(t *testing.T) {
+	images := []xcursorImage{
+		newTestXcursorImage(24, 50),
+		newTestXcursorImage(24, 50),
+		newTestXcursorImage(32, 50),
+	}
+
+	got, err := parseXcursor(encodeTestXcursor(images))
+	test.RequireNoError(t, err)
+
+	if !slices.Equal(got.sizes(), []int{24, 32}) {
+		t.Errorf("got sizes %v; want [24 32]", got.sizes())
+	}
+
+	if len(got.frames(24)) != 2 || len(got.frames(32)) != 1 {
+		t.Errorf("got %d and %d frames; want 2 and 1", len(got.frames(24)), len(got.frames(32)))
+	}
+
+	frame := got.frames(32)[0]
+	if frame.xhot != 1 || frame.yhot != 2 || frame.delay != 50 || !slices.Equal(frame.pixels, images[2].pixels) {
+		t.Errorf("frame not decoded correctly: %+v", frame)
+	}
+}
+
+func TestParseXcursorInvalid(t *testing.T) {
+	valid := encodeTestXcursor([]xcursorImage{newTestXcursorImage(24, 0)})
+
+	inputs := map[string][]byte{
+		"empty":           {},
+		"wrong magic":     append([]byte("Xbad"), valid[4:]...),
+		"truncated toc":   valid[:20],
+		"truncated image": valid[:len(valid)-10],
+	}
+
+	for description, input := range inputs {
+		t.Run(description, func(t *testing.T) {
+			if _, err := parseXcursor(input); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
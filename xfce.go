@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	xfconfXsettings = "xsettings"
+	xfconfXfwm4     = "xfwm4"
+)
+
+// xfceBackend talks to xfconfd through xfconf-query. When xfconf-query is
+// not installed, for example outside of a running session, it edits the
+// channel XML files in the config directory instead.
+type xfceBackend struct {
+	run     commandRunner
+	offline bool
+}
+
+// xfconfSetting is a single property write. kind is the xfconf type name,
+// which is the same for xfconf-query and the channel XML files.
+type xfconfSetting struct {
+	channel  string
+	property string
+	kind     string
+	value    string
+}
+
+func newXfceBackend() backend {
+	return &xfceBackend{run: runCommand, offline: !commandExists("xfconf-query")}
+}
+
+func (x *xfceBackend) name() string {
+	return "xfce"
+}
+
+func (x *xfceBackend) available() bool {
+	return !x.offline || isFile(getXfconfChannelPath(xfconfXsettings))
+}
+
+func (x *xfceBackend) capabilities() capability {
+	return capGtkTheme | capIconTheme | capCursorTheme | capCursorSize
+}
+
+func (x *xfceBackend) read() (themeConfig, error) {
+	gtkTheme, err := x.get(xfconfXsettings, "/Net/ThemeName")
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read gtk theme information: %w", err)
+	}
+
+	iconTheme, err := x.get(xfconfXsettings, "/Net/IconThemeName")
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read icon theme information: %w", err)
+	}
+
+	cursorTheme, err := x.get(xfconfXsettings, "/Gtk/CursorThemeName")
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read cursor theme information: %w", err)
+	}
+
+	cursorSize, err := x.get(xfconfXsettings, "/Gtk/CursorThemeSize")
+	if err != nil {
+		return themeConfig{}, fmt.Errorf("failed to read cursor size information: %w", err)
+	}
+
+	size, _ := strconv.Atoi(cursorSize)
+
+	return themeConfig{
+		gtkTheme:    gtkTheme,
+		iconTheme:   iconTheme,
+		cursorTheme: cursorTheme,
+		cursorSize:  size,
+	}, nil
+}
+
+func (x *xfceBackend) write(cfg themeConfig) error {
+	settings := []xfconfSetting{
+		{xfconfXsettings, "/Net/ThemeName", "string", cfg.gtkTheme},
+		{xfconfXsettings, "/Net/IconThemeName", "string", cfg.iconTheme},
+		{xfconfXsettings, "/Gtk/CursorThemeName", "string", cfg.cursorTheme},
+	}
+
+	if cfg.cursorSize > 0 {
+		settings = append(settings, xfconfSetting{xfconfXsettings, "/Gtk/CursorThemeSize", "int", strconv.Itoa(cfg.cursorSize)})
+	}
+
+	// the window manager theme only follows when the gtk theme ships one
+	if path := getAssetPath(findInstalledThemes(), cfg.gtkTheme); path != "" && isDir(filepath.Join(path, "xfwm4")) {
+		settings = append(settings, xfconfSetting{xfconfXfwm4, "/general/theme", "string", cfg.gtkTheme})
+	}
+
+	if x.offline {
+		return writeXfconfChannels(settings)
+	}
+
+	for _, s := range settings {
+		if _, err := x.run("xfconf-query", "-c", s.channel, "-p", s.property, "-n", "-t", s.kind, "-s", s.value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", s.property, err)
+		}
+	}
+
+	return nil
+}
+
+func (x *xfceBackend) get(channel, property string) (string, error) {
+	if x.offline {
+		c, err := readXfconfChannel(channel)
+		if err != nil {
+			return "", err
+		}
+
+		return c.get(property), nil
+	}
+
+	out, err := x.run("xfconf-query", "-c", channel, "-p", property)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// xfconfChannel is the perchannel XML file format of xfconfd. Array values
+// are kept so unrelated properties survive a rewrite.
+type xfconfChannel struct {
+	XMLName    xml.Name         `xml:"channel"`
+	Name       string           `xml:"name,attr"`
+	Version    string           `xml:"version,attr"`
+	Properties []xfconfProperty `xml:"property"`
+}
+
+type xfconfProperty struct {
+	Name       string           `xml:"name,attr"`
+	Type       string           `xml:"type,attr"`
+	Value      *string          `xml:"value,attr,omitempty"`
+	Values     []xfconfValue    `xml:"value"`
+	Properties []xfconfProperty `xml:"property"`
+}
+
+type xfconfValue struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func getXfconfChannelPath(channel string) string {
+	return filepath.Join(getConfigDir(), "xfce4", "xfconf", "xfce-perchannel-xml", channel+".xml")
+}
+
+func readXfconfChannel(channel string) (*xfconfChannel, error) {
+	data, err := os.ReadFile(getXfconfChannelPath(channel))
+	if errors.Is(err, os.ErrNotExist) {
+		return &xfconfChannel{Name: channel, Version: "1.0"}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXfconfChannel(data)
+}
+
+func parseXfconfChannel(data []byte) (*xfconfChannel, error) {
+	c := &xfconfChannel{}
+
+	if err := xml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid xfconf channel: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *xfconfChannel) bytes() ([]byte, error) {
+	data, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header+"\n"), append(data, '\n')...), nil
+}
+
+// find walks a property path such as /Net/ThemeName. With create set,
+// missing parents are added as empty properties, like xfconfd does.
+func (c *xfconfChannel) find(property string, create bool) *xfconfProperty {
+	props := &c.Properties
+
+	var found *xfconfProperty
+
+	for _, name := range strings.Split(strings.Trim(property, "/"), "/") {
+		found = nil
+
+		for i := range *props {
+			if (*props)[i].Name == name {
+				found = &(*props)[i]
+				break
+			}
+		}
+
+		if found == nil {
+			if !create {
+				return nil
+			}
+
+			*props = append(*props, xfconfProperty{Name: name, Type: "empty"})
+			found = &(*props)[len(*props)-1]
+		}
+
+		props = &found.Properties
+	}
+
+	return found
+}
+
+func (c *xfconfChannel) get(property string) string {
+	p := c.find(property, false)
+	if p == nil || p.Value == nil {
+		return ""
+	}
+
+	return *p.Value
+}
+
+func (c *xfconfChannel) set(property, kind, value string) {
+	p := c.find(property, true)
+
+	p.Type = kind
+	p.Value = &value
+}
+
+// writeXfconfChannels applies settings to the channel files, reading and
+// writing each channel once.
+func writeXfconfChannels(settings []xfconfSetting) error {
+	channels := []string{}
+	loaded := map[string]*xfconfChannel{}
+
+	for _, s := range settings {
+		c, ok := loaded[s.channel]
+		if !ok {
+			var err error
+
+			c, err = readXfconfChannel(s.channel)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", getXfconfChannelPath(s.channel), err)
+			}
+
+			loaded[s.channel] = c
+			channels = append(channels, s.channel)
+		}
+
+		c.set(s.property, s.kind, s.value)
+	}
+
+	for _, channel := range channels {
+		data, err := loaded[channel].bytes()
+		if err != nil {
+			return err
+		}
+
+		if err := writeConfigFile(getXfconfChannelPath(channel), data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", getXfconfChannelPath(channel), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+const sampleXsettingsXML = `<?xml version="1.0" encoding="UTF-8"?>
+
+<channel name="xsettings" version="1.0">
+  <property name="Net" type="empty">
+    <property name="ThemeName" type="string" value="Adwaita"/>
+    <property name="IconThemeName" type="string" value="elementary"/>
+  </property>
+  <property name="Xft" type="empty">
+    <property name="DPI" type="int" value="96"/>
+  </property>
+  <property name="Gtk" type="empty">
+    <property name="CursorThemeName" type="string" value="Adwaita"/>
+    <property name="DecorationLayout" type="array">
+      <value type="string" value="menu"/>
+      <value type="string" value="close"/>
+    </property>
+  </property>
+</channel>
+`
+
+func TestXfceBackendRead(t *testing.T) {
+	runner := newFakeRunner(map[string]string{
+		"xsettings /Net/ThemeName":       "Greybird",
+		"xsettings /Net/IconThemeName":   "elementary-xfce",
+		"xsettings /Gtk/CursorThemeName": "Adwaita",
+	})
+
+	b := &xfceBackend{run: runner.run}
+
+	got, err := b.read()
+	test.RequireNoError(t, err)
+
+	want := themeConfig{gtkTheme: "Greybird", iconTheme: "elementary-xfce", cursorTheme: "Adwaita"}
+
+	if got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestXfceBackendWrite(t *testing.T) {
+	themeDirPath := setupAssetDir(t, "themes")
+
+	test.CreateEmptyDir(t, filepath.Join(themeDirPath, "Greybird", "xfwm4"))
+	test.CreateEmptyFile(t, filepath.Join(themeDirPath, "Greybird", "index.theme"))
+
+	runner := newFakeRunner(nil)
+	b := &xfceBackend{run: runner.run}
+
+	err := b.write(themeConfig{gtkTheme: "Greybird", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 32})
+	test.RequireNoError(t, err)
+
+	want := []string{
+		"xfconf-query -c xsettings -p /Net/ThemeName -n -t string -s Greybird",
+		"xfconf-query -c xsettings -p /Net/IconThemeName -n -t string -s Papirus",
+		"xfconf-query -c xsettings -p /Gtk/CursorThemeName -n -t string -s Bibata",
+		"xfconf-query -c xsettings -p /Gtk/CursorThemeSize -n -t int -s 32",
+		"xfconf-query -c xfwm4 -p /general/theme -n -t string -s Greybird",
+	}
+
+	if !slices.Equal(runner.calls, want) {
+		t.Errorf("got calls %q; want %q", runner.calls, want)
+	}
+}
+
+func TestXfceBackendOffline(t *testing.T) {
+	setupAssetDir(t, "themes")
+
+	tempDir := t.TempDir()
+	t.Setenv(envConfigHome, filepath.Join(tempDir, "config"))
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+
+	path := getXfconfChannelPath(xfconfXsettings)
+	test.CreateEmptyDir(t, filepath.Dir(path))
+	test.RequireNoError(t, os.WriteFile(path, []byte(sampleXsettingsXML), 0o644))
+
+	b := &xfceBackend{offline: true}
+
+	if !b.available() {
+		t.Error("expected offline backend with a channel file to be available")
+	}
+
+	cfg := themeConfig{gtkTheme: "Greybird", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 32}
+	test.RequireNoError(t, b.write(cfg))
+
+	got, err := b.read()
+	test.RequireNoError(t, err)
+
+	if got != cfg {
+		t.Errorf("got %+v; want %+v", got, cfg)
+	}
+
+	data, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	for _, kept := range []string{`name="DPI" type="int" value="96"`, `<value type="string" value="close"></value>`} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("lost %s in rewritten channel:\n%s", kept, data)
+		}
+	}
+
+	if _, err := os.Stat(getXfconfChannelPath(xfconfXfwm4)); err == nil {
+		t.Error("xfwm4 channel written for a theme without an xfwm4 directory")
+	}
+}
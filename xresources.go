@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xresourcesFile is a line based editor for X resource files. Lines other
+// than the resources being set, including comments and preprocessor
+// directives, are kept untouched.
+type xresourcesFile struct {
+	lines []string
+}
+
+func parseXresources(data []byte) *xresourcesFile {
+	return &xresourcesFile{lines: strings.Split(string(data), "\n")}
+}
+
+func getXresourcesPath() string {
+	return filepath.Join(os.Getenv(envHome), ".Xresources")
+}
+
+func (f *xresourcesFile) find(resource string) []int {
+	indexes := []int{}
+
+	for i, line := range f.lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "!") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		name, _, ok := strings.Cut(trimmed, ":")
+		if ok && strings.TrimSpace(name) == resource {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+func (f *xresourcesFile) get(resource string) (string, bool) {
+	indexes := f.find(resource)
+	if len(indexes) == 0 {
+		return "", false
+	}
+
+	_, value, _ := strings.Cut(f.lines[indexes[len(indexes)-1]], ":")
+
+	return strings.TrimSpace(value), true
+}
+
+// set replaces every definition of resource, or appends one when the
+// resource is not defined yet.
+func (f *xresourcesFile) set(resource, value string) {
+	indexes := f.find(resource)
+
+	for _, i := range indexes {
+		f.lines[i] = resource + ": " + value
+	}
+
+	if len(indexes) > 0 {
+		return
+	}
+
+	if n := len(f.lines); n > 0 && f.lines[n-1] == "" {
+		f.lines = append(f.lines[:n-1], resource+": "+value, "")
+		return
+	}
+
+	f.lines = append(f.lines, resource+": "+value, "")
+}
+
+func (f *xresourcesFile) bytes() []byte {
+	return []byte(strings.Join(f.lines, "\n"))
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestXresourcesSet(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		want        string
+	}{
+		{
+			description: "empty file",
+			input:       "",
+			want:        "Xcursor.size: 32\n",
+		},
+		{
+			description: "existing resource",
+			input:       "! cursor\n#include \".Xresources.d/colors\"\nXcursor.size:  24\nXft.dpi: 96\n",
+			want:        "! cursor\n#include \".Xresources.d/colors\"\nXcursor.size: 32\nXft.dpi: 96\n",
+		},
+		{
+			description: "commented resource is left alone",
+			input:       "! Xcursor.size: 16\nXft.dpi: 96",
+			want:        "! Xcursor.size: 16\nXft.dpi: 96\nXcursor.size: 32\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			xres := parseXresources([]byte(tt.input))
+			xres.set("Xcursor.size", "32")
+
+			if got := string(xres.bytes()); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+
+			if value, ok := xres.get("Xcursor.size"); !ok || value != "32" {
+				t.Errorf("got %q, %t", value, ok)
+			}
+		})
+	}
+}
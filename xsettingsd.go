@@ -0,0 +1,338 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// settingsIniFonts maps the GTK settings.ini font keys onto their XSETTINGS
+// names. String values are quoted in xsettingsd.conf, the rest are numbers.
+var settingsIniFonts = []struct {
+	ini      string
+	xsetting string
+	isString bool
+}{
+	{"gtk-font-name", "Gtk/FontName", true},
+	{"gtk-xft-antialias", "Xft/Antialias", false},
+	{"gtk-xft-hinting", "Xft/Hinting", false},
+	{"gtk-xft-hintstyle", "Xft/HintStyle", true},
+	{"gtk-xft-rgba", "Xft/RGBA", true},
+	{"gtk-xft-dpi", "Xft/DPI", false},
+}
+
+// xsettingsdBackend writes the config of xsettingsd, the XSETTINGS daemon
+// used with window managers that have no settings daemon of their own, and
+// signals the running daemon to reload it.
+type xsettingsdBackend struct {
+	procRoot string
+	signal   func(pid int, sig syscall.Signal) error
+}
+
+func newXsettingsdBackend() backend {
+	return &xsettingsdBackend{procRoot: "/proc", signal: syscall.Kill}
+}
+
+func getXsettingsdPath() string {
+	return filepath.Join(getConfigDir(), "xsettingsd", "xsettingsd.conf")
+}
+
+func (x *xsettingsdBackend) name() string {
+	return "xsettingsd"
+}
+
+func (x *xsettingsdBackend) available() bool {
+	return commandExists("xsettingsd") || isFile(getXsettingsdPath())
+}
+
+func (x *xsettingsdBackend) capabilities() capability {
+	return capGtkTheme | capIconTheme | capCursorTheme | capCursorSize
+}
+
+func (x *xsettingsdBackend) read() (themeConfig, error) {
+	data, err := os.ReadFile(getXsettingsdPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return themeConfig{}, fmt.Errorf("failed to read %s: %w", getXsettingsdPath(), err)
+	}
+
+	conf := parseXsettingsd(data)
+
+	size, _ := strconv.Atoi(conf.get("Gtk/CursorThemeSize"))
+
+	return themeConfig{
+		gtkTheme:    conf.get("Net/ThemeName"),
+		iconTheme:   conf.get("Net/IconThemeName"),
+		cursorTheme: conf.get("Gtk/CursorThemeName"),
+		cursorSize:  size,
+	}, nil
+}
+
+func (x *xsettingsdBackend) write(cfg themeConfig) error {
+	path := getXsettingsdPath()
+
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	conf := parseXsettingsd(old)
+
+	conf.setString("Net/ThemeName", cfg.gtkTheme)
+	conf.setString("Net/IconThemeName", cfg.iconTheme)
+	conf.setString("Gtk/CursorThemeName", cfg.cursorTheme)
+
+	if cfg.cursorSize > 0 {
+		conf.set("Gtk/CursorThemeSize", strconv.Itoa(cfg.cursorSize))
+	}
+
+	if err := copySettingsIniFonts(conf); err != nil {
+		return err
+	}
+
+	if err := writeConfigFile(path, conf.bytes()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return x.reload()
+}
+
+// copySettingsIniFonts fills in the font settings xsettingsd.conf lacks
+// from the GTK 3 settings.ini, since xsettingsd overrides GTK's own
+// settings with its defaults for anything it serves.
+func copySettingsIniFonts(conf *xsettingsdFile) error {
+	data, err := os.ReadFile(filepath.Join(getConfigDir(), "gtk-3.0", "settings.ini"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read font settings: %w", err)
+	}
+
+	ini := parseINI(data)
+
+	for _, font := range settingsIniFonts {
+		if _, ok := conf.lookup(font.xsetting); ok {
+			continue
+		}
+
+		value, ok := ini.get("Settings", font.ini)
+		if !ok || value == "" {
+			continue
+		}
+
+		if font.isString {
+			conf.setString(font.xsetting, strings.Trim(value, `"`))
+		} else if _, err := strconv.Atoi(value); err == nil {
+			conf.set(font.xsetting, value)
+		}
+	}
+
+	return nil
+}
+
+// reload sends SIGHUP to every xsettingsd process of the current user,
+// which makes it reread its config and notify clients of the changes.
+func (x *xsettingsdBackend) reload() error {
+	pids, err := findProcesses(x.procRoot, "xsettingsd")
+	if err != nil {
+		return err
+	}
+
+	for _, pid := range pids {
+		if err := x.signal(pid, syscall.SIGHUP); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to signal xsettingsd (pid %d): %w", pid, err)
+		}
+	}
+
+	return nil
+}
+
+// findProcesses returns the pids of the current user's processes with the
+// given command name.
+func findProcesses(procRoot, command string) ([]int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	pids := []int{}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil || strings.TrimSpace(string(comm)) != command {
+			continue
+		}
+
+		status, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "status"))
+		if err != nil {
+			continue
+		}
+
+		for _, line := range strings.Split(string(status), "\n") {
+			// Uid: real, effective, saved, filesystem
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "Uid:" && fields[1] == uid {
+				pids = append(pids, pid)
+			}
+		}
+	}
+
+	return pids, nil
+}
+
+// xsettingsdLine is one line of xsettingsd.conf. Setting lines are split so
+// a value can be replaced while keeping a trailing comment.
+type xsettingsdLine struct {
+	raw     string
+	name    string
+	value   string
+	comment string
+}
+
+// xsettingsdFile is a line based editor for xsettingsd.conf, which holds
+// one "Name value" setting per line; strings are double quoted.
+type xsettingsdFile struct {
+	lines []xsettingsdLine
+}
+
+func parseXsettingsd(data []byte) *xsettingsdFile {
+	f := &xsettingsdFile{}
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		f.lines = append(f.lines, parseXsettingsdLine(raw))
+	}
+
+	return f
+}
+
+func parseXsettingsdLine(raw string) xsettingsdLine {
+	line := xsettingsdLine{raw: raw}
+
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return line
+	}
+
+	name, rest := trimmed, ""
+	if i := strings.IndexAny(trimmed, " \t"); i >= 0 {
+		name, rest = trimmed[:i], trimmed[i+1:]
+	}
+
+	// a comment starts at the first # outside of a quoted string
+	end := strings.IndexFunc(rest, xsettingsdCommentStart())
+	if end < 0 {
+		end = len(rest)
+	}
+
+	line.name = name
+	line.value = strings.TrimSpace(rest[:end])
+	line.comment = rest[end:]
+
+	return line
+}
+
+func xsettingsdCommentStart() func(rune) bool {
+	inString, escaped := false, false
+
+	return func(c rune) bool {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = inString
+		case c == '"':
+			inString = !inString
+		case c == '#':
+			return !inString
+		}
+
+		return false
+	}
+}
+
+func (f *xsettingsdFile) lookup(name string) (string, bool) {
+	value, found := "", false
+
+	for _, line := range f.lines {
+		if line.name == name {
+			value, found = line.value, true
+		}
+	}
+
+	return value, found
+}
+
+// get returns a setting with string quoting and escapes removed.
+func (f *xsettingsdFile) get(name string) string {
+	value, _ := f.lookup(name)
+
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		replacer := strings.NewReplacer(`\"`, `"`, `\\`, `\`)
+		return replacer.Replace(value[1 : len(value)-1])
+	}
+
+	return value
+}
+
+// set replaces every definition of name, or appends it to the file.
+func (f *xsettingsdFile) set(name, value string) {
+	found := false
+
+	for i, line := range f.lines {
+		if line.name != name {
+			continue
+		}
+
+		found = true
+
+		if line.value == value {
+			continue
+		}
+
+		raw := name + " " + value
+		if line.comment != "" {
+			raw += " " + line.comment
+		}
+
+		f.lines[i] = xsettingsdLine{raw: raw, name: name, value: value, comment: line.comment}
+	}
+
+	if found {
+		return
+	}
+
+	newLine := xsettingsdLine{raw: name + " " + value, name: name, value: value}
+
+	if n := len(f.lines); n > 0 && f.lines[n-1].raw == "" {
+		f.lines = append(f.lines[:n-1], newLine, xsettingsdLine{})
+		return
+	}
+
+	f.lines = append(f.lines, newLine, xsettingsdLine{})
+}
+
+func (f *xsettingsdFile) setString(name, value string) {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	f.set(name, `"`+replacer.Replace(value)+`"`)
+}
+
+func (f *xsettingsdFile) bytes() []byte {
+	raws := make([]string, len(f.lines))
+	for i, line := range f.lines {
+		raws[i] = line.raw
+	}
+
+	return []byte(strings.Join(raws, "\n"))
+}
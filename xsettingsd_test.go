@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/badiwidya/lookctl/test"
+)
+
+const sampleXsettingsdConf = `# written by hand
+Net/ThemeName "Adwaita" # the gtk theme
+Net/IconThemeName "Adwaita"
+Xft/DPI 98304
+Net/DoubleClickTime 250
+`
+
+func setupXsettingsd(t *testing.T) string {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	t.Setenv(envConfigHome, filepath.Join(tempDir, "config"))
+	t.Setenv(envStateHome, filepath.Join(tempDir, "state"))
+
+	return filepath.Join(tempDir, "proc")
+}
+
+func createFakeProcess(t *testing.T, procRoot string, pid int, comm string, uid int) {
+	t.Helper()
+
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	test.CreateEmptyDir(t, dir)
+
+	status := "Name:\t" + comm + "\nUid:\t" + strconv.Itoa(uid) + "\t" + strconv.Itoa(uid) + "\t0\t0\n"
+
+	test.RequireNoError(t, os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0o644))
+	test.RequireNoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644))
+}
+
+func TestXsettingsdFile(t *testing.T) {
+	conf := parseXsettingsd([]byte(sampleXsettingsdConf))
+
+	if got := conf.get("Net/ThemeName"); got != "Adwaita" {
+		t.Errorf("got theme %q; want %q", got, "Adwaita")
+	}
+
+	if got := conf.get("Xft/DPI"); got != "98304" {
+		t.Errorf("got dpi %q; want %q", got, "98304")
+	}
+
+	conf.setString("Net/ThemeName", `Odd "Theme" #1`)
+	conf.set("Gtk/CursorThemeSize", "32")
+
+	if got := conf.get("Net/ThemeName"); got != `Odd "Theme" #1` {
+		t.Errorf("got theme %q after set", got)
+	}
+
+	want := `# written by hand
+Net/ThemeName "Odd \"Theme\" #1" # the gtk theme
+Net/IconThemeName "Adwaita"
+Xft/DPI 98304
+Net/DoubleClickTime 250
+Gtk/CursorThemeSize 32
+`
+
+	if got := string(conf.bytes()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestXsettingsdBackendWrite(t *testing.T) {
+	procRoot := setupXsettingsd(t)
+
+	createFakeProcess(t, procRoot, 100, "xsettingsd", os.Getuid())
+	createFakeProcess(t, procRoot, 101, "xsettingsd", os.Getuid()+1)
+	createFakeProcess(t, procRoot, 102, "i3", os.Getuid())
+	test.CreateEmptyDir(t, filepath.Join(procRoot, "self"))
+
+	path := getXsettingsdPath()
+	test.CreateEmptyDir(t, filepath.Dir(path))
+	test.RequireNoError(t, os.WriteFile(path, []byte(sampleXsettingsdConf), 0o644))
+
+	signalled := []int{}
+	b := &xsettingsdBackend{
+		procRoot: procRoot,
+		signal: func(pid int, sig syscall.Signal) error {
+			if sig != syscall.SIGHUP {
+				t.Errorf("got signal %v; want SIGHUP", sig)
+			}
+
+			signalled = append(signalled, pid)
+
+			return nil
+		},
+	}
+
+	cfg := themeConfig{gtkTheme: "Arc", iconTheme: "Papirus", cursorTheme: "Bibata", cursorSize: 32}
+	test.RequireNoError(t, b.write(cfg))
+
+	if !slices.Equal(signalled, []int{100}) {
+		t.Errorf("signalled %v; want [100]", signalled)
+	}
+
+	got, err := b.read()
+	test.RequireNoError(t, err)
+
+	if got != cfg {
+		t.Errorf("got %+v; want %+v", got, cfg)
+	}
+
+	data, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	conf := parseXsettingsd(data)
+
+	if got := conf.get("Net/DoubleClickTime"); got != "250" {
+		t.Errorf("lost Net/DoubleClickTime, got %q:\n%s", got, data)
+	}
+}
+
+func TestXsettingsdBackendCopiesFonts(t *testing.T) {
+	procRoot := setupXsettingsd(t)
+	test.CreateEmptyDir(t, procRoot)
+
+	settings := `[Settings]
+gtk-theme-name=Adwaita
+gtk-font-name=Cantarell 11
+gtk-xft-antialias=1
+gtk-xft-hintstyle=hintslight
+gtk-xft-rgba=rgb
+gtk-xft-dpi=98304
+`
+
+	settingsPath := filepath.Join(getConfigDir(), "gtk-3.0", "settings.ini")
+	test.CreateEmptyDir(t, filepath.Dir(settingsPath))
+	test.RequireNoError(t, os.WriteFile(settingsPath, []byte(settings), 0o644))
+
+	path := getXsettingsdPath()
+	test.CreateEmptyDir(t, filepath.Dir(path))
+	test.RequireNoError(t, os.WriteFile(path, []byte("Xft/DPI 110592\n"), 0o644))
+
+	b := &xsettingsdBackend{procRoot: procRoot, signal: syscall.Kill}
+
+	test.RequireNoError(t, b.write(themeConfig{gtkTheme: "Arc", iconTheme: "Papirus", cursorTheme: "Bibata"}))
+
+	data, err := os.ReadFile(path)
+	test.RequireNoError(t, err)
+
+	want := `Xft/DPI 110592
+Net/ThemeName "Arc"
+Net/IconThemeName "Papirus"
+Gtk/CursorThemeName "Bibata"
+Gtk/FontName "Cantarell 11"
+Xft/Antialias 1
+Xft/HintStyle "hintslight"
+Xft/RGBA "rgb"
+`
+
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}